@@ -29,4 +29,24 @@ resource "hiveio_disk" "upload-test" {
   storage_pool = storage_pool_id
   local_file   = "virtio-win.iso"
   format       = "raw"
-}
\ No newline at end of file
+}
+
+#Import a VMware OVA and use the descriptor for the template
+resource "hiveio_disk" "appliance" {
+  filename     = "appliance.qcow2"
+  storage_pool = storage_pool_id
+  src_storage  = storage_pool_id
+  src_filename = "exports/appliance.ova"
+}
+
+resource "hiveio_template" "appliance" {
+  name     = "appliance"
+  os       = "linux"
+  cpu      = hiveio_disk.appliance.ova[0].cpu
+  mem      = hiveio_disk.appliance.ova[0].memory
+  firmware = hiveio_disk.appliance.ova[0].firmware
+  disk {
+    storage_id = storage_pool_id
+    filename   = hiveio_disk.appliance.filename
+  }
+}
@@ -11,6 +11,15 @@ resource "hiveio_guest_pool" "win10_pool" {
   persistent   = false
   storage_type = "nfs"
   storage_id   = hiveio_storage_pool.vms.id
+  schedule {
+    timezone = "America/New_York"
+    window {
+      days    = ["mon", "tue", "wed", "thu", "fri"]
+      start   = "07:00"
+      end     = "18:00"
+      density = [4, 8]
+    }
+  }
 }
 
 #Create a non-persistent ubuntu pool on disk
@@ -30,4 +39,9 @@ resource "hiveio_guest_pool" "ubuntu_pool" {
     frequency = "daily"
     target    = hiveio_storage_pool.backup.id
   }
+  rollout {
+    strategy        = "rolling"
+    max_unavailable = 2
+    batch_interval  = 60
+  }
 }
\ No newline at end of file
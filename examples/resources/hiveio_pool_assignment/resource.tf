@@ -0,0 +1,21 @@
+# Allow an active directory group to get desktops from a pool
+resource "hiveio_pool_assignment" "win10_users" {
+  pool     = hiveio_guest_pool.win10_pool.id
+  realm    = hiveio_realm.realm1.name
+  ad_group = "Domain Users"
+}
+
+# Pin users to specific guests in a persistent pool
+resource "hiveio_pool_assignment" "developers" {
+  pool     = hiveio_guest_pool.dev_pool.id
+  realm    = hiveio_realm.realm1.name
+  ad_group = "Developers"
+  guest_assignment {
+    guest    = "DEV-001"
+    username = "user1"
+  }
+  guest_assignment {
+    guest    = "DEV-002"
+    username = "user2"
+  }
+}
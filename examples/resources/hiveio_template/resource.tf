@@ -39,4 +39,14 @@ resource "hiveio_template" "ubuntu_server" {
     network   = "prod"
     vlan      = 0
   }
-}
\ No newline at end of file
+}
+
+# Import a template exported from another cluster
+resource "hiveio_template" "win10_imported" {
+  name = "win10-v2"
+  os   = "win10"
+  source {
+    storage_id = hiveio_storage_pool.vms.id
+    path       = "exports/win10-v2"
+  }
+}
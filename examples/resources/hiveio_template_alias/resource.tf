@@ -0,0 +1,18 @@
+# Promote a version by changing the alias, pools using the alias are updated
+resource "hiveio_template_alias" "win10_prod" {
+  name    = "win10-prod"
+  version = hiveio_template_version.win10_v2.name
+}
+
+resource "hiveio_guest_pool" "win10_prod" {
+  name         = "win10-prod"
+  density      = [2, 10]
+  seed         = "W10PROD"
+  template     = hiveio_template_alias.win10_prod.template
+  profile      = hiveio_profile.default_profile.id
+  storage_type = "disk"
+  storage_id   = "disk"
+  rollout {
+    strategy = "rolling"
+  }
+}
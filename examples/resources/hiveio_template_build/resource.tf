@@ -0,0 +1,8 @@
+# Boot the template for authoring, wait for the agent, and seal it with sysprep
+resource "hiveio_template_build" "win10_uefi" {
+  template = hiveio_template.win10_uefi.name
+  seal     = "guest"
+  triggers = {
+    image = "win10-1909-uefi.qcow2"
+  }
+}
@@ -0,0 +1,6 @@
+# Export a template to nfs so it can be imported on another cluster
+resource "hiveio_template_export" "win10_v2" {
+  template   = hiveio_template_version.win10_v2.name
+  storage_id = hiveio_storage_pool.vms.id
+  path       = "exports/win10-v2"
+}
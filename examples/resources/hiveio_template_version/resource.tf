@@ -0,0 +1,6 @@
+# Snapshot the authored template as a new version
+resource "hiveio_template_version" "win10_v2" {
+  template   = hiveio_template.win10_uefi.name
+  version    = "v2"
+  storage_id = hiveio_storage_pool.vms.id
+}
@@ -22,4 +22,30 @@ resource "hiveio_virtual_machine" "win10" {
     frequency = "daily"
     target    = hiveio_storage_pool.backup.id
   }
-}
\ No newline at end of file
+}
+
+# Roll a virtual machine back to its most recent backup. restore_from forces
+# a replacement, and the backup must belong to the guest being restored.
+data "hiveio_backups" "kiosk" {
+  guest = "KIOSK"
+}
+
+resource "hiveio_virtual_machine" "kiosk" {
+  name   = "kiosk"
+  cpu    = 2
+  memory = 4096
+  os     = "win10"
+  disk {
+    storage_id = hiveio_storage_pool.vms.id
+    filename   = "kiosk.qcow2"
+  }
+  backup {
+    enabled   = true
+    frequency = "daily"
+    target    = hiveio_storage_pool.backup.id
+  }
+  restore_from {
+    backup     = data.hiveio_backups.kiosk.backups[length(data.hiveio_backups.kiosk.backups) - 1].name
+    storage_id = hiveio_storage_pool.backup.id
+  }
+}
@@ -0,0 +1,153 @@
+package hiveio
+
+import (
+	"context"
+	"net/url"
+	"path"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
+)
+
+func dataSourceBackups() *schema.Resource {
+	return &schema.Resource{
+		Description: "The backups data source lists the backups available for a guest or for every guest in a pool.",
+		ReadContext: dataSourceBackupsRead,
+		Schema: map[string]*schema.Schema{
+			"guest": {
+				Type:        schema.TypeString,
+				Description: "The name of the guest to list backups for.",
+				Optional:    true,
+			},
+			"pool": {
+				Type:        schema.TypeString,
+				Description: "The id of a pool or virtual machine to list backups for.",
+				Optional:    true,
+			},
+			"storage_id": {
+				Type:        schema.TypeString,
+				Description: "The storage pool id containing the backups. Defaults to the backup target of the guest.",
+				Optional:    true,
+			},
+			"backups": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"guest": {
+							Type:        schema.TypeString,
+							Description: "name of the guest the backup belongs to",
+							Computed:    true,
+						},
+						"storage_id": {
+							Type:        schema.TypeString,
+							Description: "storage pool id containing the backup",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "name of the backup to use for a restore",
+							Computed:    true,
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Description: "modification time of the backup file",
+							Computed:    true,
+						},
+						"size": {
+							Type:        schema.TypeInt,
+							Description: "size of the backup file in bytes",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+func dataSourceBackupsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var guests []rest.Guest
+	var id string
+	if name, ok := d.GetOk("guest"); ok {
+		guest, err := client.GetGuest(name.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		guests = append(guests, *guest)
+		id = guest.Name
+	} else if poolID, ok := d.GetOk("pool"); ok {
+		guests, err = client.ListGuests("poolId=" + url.QueryEscape(poolID.(string)))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		id = poolID.(string)
+	} else {
+		return diag.Errorf("guest or pool must be provided")
+	}
+
+	storagePools := map[string]*rest.StoragePool{}
+	files := map[string][]rest.StoragePoolFileInfo{}
+	backups := []interface{}{}
+	for _, guest := range guests {
+		storageID := d.Get("storage_id").(string)
+		if storageID == "" && guest.Backup != nil {
+			storageID = guest.Backup.TargetStorageID
+		}
+		if storageID == "" {
+			continue
+		}
+		names, err := guest.ListBackups(client, storageID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		storage, ok := storagePools[storageID]
+		if !ok {
+			storage, err = client.GetStoragePool(storageID)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			storagePools[storageID] = storage
+		}
+		for _, name := range names {
+			backup := map[string]interface{}{
+				"guest":      guest.Name,
+				"storage_id": storageID,
+				"name":       name,
+			}
+			//size and time are not part of the backup list, look them up from the storage pool
+			dir := path.Dir(name)
+			if dir == "." {
+				dir = ""
+			}
+			key := storageID + "/" + dir
+			if _, ok := files[key]; !ok {
+				files[key], err = storage.Browse(client, dir, false)
+				if err != nil {
+					return diag.FromErr(err)
+				}
+			}
+			for _, file := range files[key] {
+				if file.Name == path.Base(name) {
+					backup["timestamp"] = file.ModTime
+					backup["size"] = file.Size
+					break
+				}
+			}
+			backups = append(backups, backup)
+		}
+	}
+
+	if err := d.Set("backups", backups); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(id)
+	return diag.Diagnostics{}
+}
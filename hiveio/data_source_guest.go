@@ -0,0 +1,117 @@
+package hiveio
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
+)
+
+// guestAttributes are the computed attributes shared by the hiveio_guest and hiveio_guests data sources
+func guestAttributes() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Description: "name of the guest",
+			Computed:    true,
+		},
+		"state": {
+			Type:        schema.TypeString,
+			Description: "current state of the guest",
+			Computed:    true,
+		},
+		"hostid": {
+			Type:        schema.TypeString,
+			Description: "id of the host running the guest",
+			Computed:    true,
+		},
+		"pool_id": {
+			Type:        schema.TypeString,
+			Description: "id of the pool the guest belongs to",
+			Computed:    true,
+		},
+		"ip_addresses": {
+			Type:        schema.TypeList,
+			Description: "ip addresses reported by the guest",
+			Computed:    true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"mac_addresses": {
+			Type:        schema.TypeList,
+			Description: "mac addresses of the guest interfaces",
+			Computed:    true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"username": {
+			Type:        schema.TypeString,
+			Description: "user assigned to the guest",
+			Computed:    true,
+		},
+		"agent_version": {
+			Type:        schema.TypeString,
+			Description: "version of the guest agent",
+			Computed:    true,
+		},
+	}
+}
+
+func flattenGuest(guest *rest.Guest) map[string]interface{} {
+	ips := []string{}
+	macs := []string{}
+	for _, iface := range guest.Interfaces {
+		if iface.IPAddress != "" {
+			ips = append(ips, iface.IPAddress)
+		}
+		if iface.MacAddress != "" {
+			macs = append(macs, iface.MacAddress)
+		}
+	}
+	return map[string]interface{}{
+		"name":          guest.Name,
+		"state":         guest.GuestState,
+		"hostid":        guest.Hostid,
+		"pool_id":       guest.PoolID,
+		"ip_addresses":  ips,
+		"mac_addresses": macs,
+		"username":      guest.Username,
+		"agent_version": guest.AgentVersion,
+	}
+}
+
+func dataSourceGuest() *schema.Resource {
+	guestSchema := guestAttributes()
+	guestSchema["name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Description: "The name of the guest.",
+		Required:    true,
+	}
+	guestSchema["provider_override"] = &providerOverride
+	return &schema.Resource{
+		Description: "A data source to retrieve guest information by name.",
+		ReadContext: dataSourceGuestRead,
+		Schema:      guestSchema,
+	}
+}
+
+func dataSourceGuestRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	guest, err := client.GetGuest(d.Get("name").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for key, value := range flattenGuest(guest) {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	d.SetId(guest.Name)
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,93 @@
+package hiveio
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceGuests() *schema.Resource {
+	return &schema.Resource{
+		Description: "The guests data source lists guests matching the provided filters.",
+		ReadContext: dataSourceGuestsRead,
+		Schema: map[string]*schema.Schema{
+			"pool_id": {
+				Type:        schema.TypeString,
+				Description: "Only return guests in this pool.",
+				Optional:    true,
+			},
+			"hostid": {
+				Type:        schema.TypeString,
+				Description: "Only return guests running on this host.",
+				Optional:    true,
+			},
+			"state": {
+				Type:        schema.TypeString,
+				Description: "Only return guests in this state.",
+				Optional:    true,
+			},
+			"username": {
+				Type:        schema.TypeString,
+				Description: "Only return guests assigned to this user.",
+				Optional:    true,
+			},
+			"name_prefix": {
+				Type:        schema.TypeString,
+				Description: "Only return guests with names starting with this prefix.",
+				Optional:    true,
+			},
+			"guests": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: guestAttributes(),
+				},
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+func dataSourceGuestsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	query := ""
+	if poolID, ok := d.GetOk("pool_id"); ok {
+		query = "poolId=" + url.QueryEscape(poolID.(string))
+	}
+	list, err := client.ListGuests(query)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	hostid := d.Get("hostid").(string)
+	state := d.Get("state").(string)
+	username := d.Get("username").(string)
+	prefix := d.Get("name_prefix").(string)
+	guests := []interface{}{}
+	for _, guest := range list {
+		if hostid != "" && guest.Hostid != hostid {
+			continue
+		}
+		if state != "" && guest.GuestState != state {
+			continue
+		}
+		if username != "" && guest.Username != username {
+			continue
+		}
+		if !strings.HasPrefix(guest.Name, prefix) {
+			continue
+		}
+		guests = append(guests, flattenGuest(&guest))
+	}
+	if err := d.Set("guests", guests); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strings.Join([]string{d.Get("pool_id").(string), hostid, state, username, prefix}, "/"))
+	return diag.Diagnostics{}
+}
@@ -33,6 +33,13 @@ func dataSourceHost() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"tags": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"provider_override": &providerOverride,
 		},
 	}
@@ -65,6 +72,7 @@ func dataSourceHostRead(ctx context.Context, d *schema.ResourceData, m interface
 	d.Set("hostid", host.Hostid)
 	d.Set("cluster_id", host.Appliance.ClusterID)
 	d.Set("software_version", host.Appliance.Firmware.Software)
+	d.Set("tags", host.Tags)
 	d.SetId(host.Hostid)
 	return diag.Diagnostics{}
 }
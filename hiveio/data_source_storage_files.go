@@ -0,0 +1,205 @@
+package hiveio
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceStorageFiles() *schema.Resource {
+	return &schema.Resource{
+		Description: "The storage files data source lists files in a storage pool with disk details.",
+		ReadContext: dataSourceStorageFilesRead,
+		Schema: map[string]*schema.Schema{
+			"storage_id": {
+				Type:        schema.TypeString,
+				Description: "The id of the storage pool.",
+				Required:    true,
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Description: "The directory to list. Defaults to the root of the storage pool.",
+				Optional:    true,
+			},
+			"recursive": {
+				Type:        schema.TypeBool,
+				Description: "List files in subdirectories.",
+				Default:     false,
+				Optional:    true,
+			},
+			"glob": {
+				Type:          schema.TypeString,
+				Description:   "Only return files with a name matching this shell pattern, such as ubuntu-*.qcow2.",
+				Optional:      true,
+				ConflictsWith: []string{"regex"},
+			},
+			"regex": {
+				Type:          schema.TypeString,
+				Description:   "Only return files with a name matching this regular expression.",
+				Optional:      true,
+				ConflictsWith: []string{"glob"},
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if _, err := regexp.Compile(val.(string)); err != nil {
+						errs = append(errs, err)
+					}
+					return
+				},
+			},
+			"sort_by": {
+				Type:        schema.TypeString,
+				Description: "Sort the files by 'name' or by 'mod_time' with the newest file first.",
+				Default:     "name",
+				Optional:    true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if v != "name" && v != "mod_time" {
+						errs = append(errs, fmt.Errorf("%q must be name or mod_time", key))
+					}
+					return
+				},
+			},
+			"files": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Description: "name of the file",
+							Computed:    true,
+						},
+						"filename": {
+							Type:        schema.TypeString,
+							Description: "path of the file in the storage pool, usable as a disk filename",
+							Computed:    true,
+						},
+						"mod_time": {
+							Type:        schema.TypeString,
+							Description: "modification time of the file in RFC 3339 format",
+							Computed:    true,
+						},
+						"format": {
+							Type:        schema.TypeString,
+							Description: "disk format, empty for files that are not disk images. Files ending in .qcow2, .img, .raw, .vmdk, .vhd, or .vhdx are read as disk images",
+							Computed:    true,
+						},
+						"virtual_size": {
+							Type:        schema.TypeInt,
+							Description: "virtual size of the disk in bytes",
+							Computed:    true,
+						},
+						"actual_size": {
+							Type:        schema.TypeInt,
+							Description: "size of the file in bytes",
+							Computed:    true,
+						},
+						"backing_filename": {
+							Type:        schema.TypeString,
+							Description: "backing file of the disk",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+// diskImageExtensions are the file extensions read with DiskInfo
+var diskImageExtensions = []string{".qcow2", ".img", ".raw", ".vmdk", ".vhd", ".vhdx"}
+
+func dataSourceStorageFilesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	storage, err := client.GetStoragePool(d.Get("storage_id").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	dir := d.Get("path").(string)
+	list, err := storage.Browse(client, dir, d.Get("recursive").(bool))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	glob := d.Get("glob").(string)
+	var re *regexp.Regexp
+	if expr, ok := d.GetOk("regex"); ok {
+		re = regexp.MustCompile(expr.(string))
+	}
+
+	type storageFile struct {
+		entry   map[string]interface{}
+		modTime time.Time
+	}
+	var matches []storageFile
+	for _, file := range list {
+		if file.IsDir {
+			continue
+		}
+		if glob != "" {
+			if match, err := path.Match(glob, file.Name); err != nil {
+				return diag.FromErr(err)
+			} else if !match {
+				continue
+			}
+		}
+		if re != nil && !re.MatchString(file.Name) {
+			continue
+		}
+		filename := file.Path
+		if filename == "" {
+			filename = path.Join(dir, file.Name)
+		}
+		modTime, err := time.Parse(time.RFC3339Nano, file.ModTime)
+		if err != nil {
+			return diag.Errorf("failed to parse the modification time of %s: %s", filename, err)
+		}
+		entry := map[string]interface{}{
+			"name":        file.Name,
+			"filename":    filename,
+			"mod_time":    modTime.Format(time.RFC3339Nano),
+			"actual_size": file.Size,
+		}
+		//only disk images have disk info
+		if slices.Contains(diskImageExtensions, strings.ToLower(path.Ext(file.Name))) {
+			info, err := storage.DiskInfo(client, filename)
+			if err != nil {
+				return diag.Errorf("failed to read disk info for %s: %s", filename, err)
+			}
+			entry["format"] = info.Format
+			entry["virtual_size"] = int(info.VirtualSize)
+			entry["actual_size"] = int(info.ActualSize)
+			entry["backing_filename"] = info.BackingFilename
+		}
+		matches = append(matches, storageFile{entry: entry, modTime: modTime})
+	}
+	if d.Get("sort_by").(string) == "mod_time" {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].modTime.After(matches[j].modTime)
+		})
+	} else {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].entry["filename"].(string) < matches[j].entry["filename"].(string)
+		})
+	}
+	files := make([]map[string]interface{}, len(matches))
+	for i, match := range matches {
+		files[i] = match.entry
+	}
+
+	if err := d.Set("files", files); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(storage.ID + "/" + dir)
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,180 @@
+package hiveio
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceStoragePools() *schema.Resource {
+	return &schema.Resource{
+		Description: "The storage pools data source lists storage pools matching the provided filters, sorted by name.",
+		ReadContext: dataSourceStoragePoolsRead,
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Type:        schema.TypeString,
+				Description: "Only return storage pools of this type.",
+				Optional:    true,
+			},
+			"role": {
+				Type:        schema.TypeString,
+				Description: "Only return storage pools with this role.",
+				Optional:    true,
+			},
+			"tag": {
+				Type:        schema.TypeString,
+				Description: "Only return storage pools with this tag.",
+				Optional:    true,
+			},
+			"host": {
+				Type:        schema.TypeString,
+				Description: "Only return storage pools added to this host id.",
+				Optional:    true,
+			},
+			"storage_pools": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"server": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"url": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"username": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"s3_region": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"device": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"fs_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"disabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"mount_options": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"roles": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"tags": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"hosts": {
+							Type:        schema.TypeList,
+							Description: "host ids the storage pool is limited to, empty when it is added to all hosts",
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+func dataSourceStoragePoolsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	list, err := client.ListStoragePools("")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	poolType := d.Get("type").(string)
+	role := d.Get("role").(string)
+	tag := d.Get("tag").(string)
+	host := d.Get("host").(string)
+	pools := []interface{}{}
+	for _, storage := range list {
+		if poolType != "" && storage.Type != poolType {
+			continue
+		}
+		if role != "" && !slices.Contains(storage.Roles, role) {
+			continue
+		}
+		if tag != "" && !slices.Contains(storage.Tags, tag) {
+			continue
+		}
+		//pools without hosts are added to every host
+		if host != "" && len(storage.Hosts) > 0 && !slices.Contains(storage.Hosts, host) {
+			continue
+		}
+		entry := map[string]interface{}{
+			"id":            storage.ID,
+			"name":          storage.Name,
+			"type":          storage.Type,
+			"server":        storage.Server,
+			"path":          storage.Path,
+			"url":           storage.URL,
+			"username":      storage.Username,
+			"s3_region":     storage.S3Region,
+			"device":        storage.Device,
+			"fs_name":       storage.FSName,
+			"disabled":      storage.Disabled,
+			"mount_options": storage.MountOptions,
+			"roles":         storage.Roles,
+			"tags":          storage.Tags,
+			"hosts":         storage.Hosts,
+		}
+		pools = append(pools, entry)
+	}
+	if err := d.Set("storage_pools", pools); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(strings.Join([]string{poolType, role, tag, host}, "/"))
+	return diag.Diagnostics{}
+}
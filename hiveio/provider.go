@@ -5,6 +5,7 @@ import (
 	"log"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hive-io/hive-go-client/rest"
 )
@@ -82,7 +83,12 @@ var providerOverride = schema.Schema{
 	},
 }
 
-func getClient(d *schema.ResourceData, m interface{}) (*rest.Client, error) {
+// resourceGetter is implemented by both schema.ResourceData and schema.ResourceDiff
+type resourceGetter interface {
+	GetOk(key string) (interface{}, bool)
+}
+
+func getClient(d resourceGetter, m interface{}) (*rest.Client, error) {
 	if override, ok := d.GetOk("provider_override"); ok {
 		settings := override.([]interface{})[0].(map[string]interface{})
 		host := settings["host"].(string)
@@ -127,28 +133,38 @@ func Provider() *schema.Provider {
 
 		Schema: providerSchema,
 		DataSourcesMap: map[string]*schema.Resource{
-			"hiveio_profile":      dataSourceProfile(),
-			"hiveio_storage_pool": dataSourceStoragePool(),
-			"hiveio_host":         dataSourceHost(),
-			"hiveio_host_network": dataSourceHostNetwork(),
-			"hiveio_version":      dataSourceVersion(),
+			"hiveio_profile":       dataSourceProfile(),
+			"hiveio_storage_pool":  dataSourceStoragePool(),
+			"hiveio_host":          dataSourceHost(),
+			"hiveio_host_network":  dataSourceHostNetwork(),
+			"hiveio_version":       dataSourceVersion(),
+			"hiveio_backups":       dataSourceBackups(),
+			"hiveio_guest":         dataSourceGuest(),
+			"hiveio_guests":        dataSourceGuests(),
+			"hiveio_storage_files": dataSourceStorageFiles(),
+			"hiveio_storage_pools": dataSourceStoragePools(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"hiveio_host":            resourceHost(),
-			"hiveio_realm":           resourceRealm(),
-			"hiveio_profile":         resourceProfile(),
-			"hiveio_storage_pool":    resourceStoragePool(),
-			"hiveio_disk":            resourceDisk(),
-			"hiveio_template":        resourceTemplate(),
-			"hiveio_guest_pool":      resourceGuestPool(),
-			"hiveio_virtual_machine": resourceVM(),
-			"hiveio_license":         resourceLicense(),
-			"hiveio_external_guest":  resourceExternalGuest(),
-			"hiveio_user":            resourceUser(),
-			"hiveio_shared_storage":  resourceSharedStorage(),
-			"hiveio_host_network":    resourceHostNetwork(),
-			"hiveio_host_iscsi":      resourceHostIscsi(),
-			"hiveio_gateway_host":    resourceGatewayHost(),
+			"hiveio_host":             resourceHost(),
+			"hiveio_realm":            resourceRealm(),
+			"hiveio_profile":          resourceProfile(),
+			"hiveio_storage_pool":     resourceStoragePool(),
+			"hiveio_disk":             resourceDisk(),
+			"hiveio_template":         resourceTemplate(),
+			"hiveio_template_build":   resourceTemplateBuild(),
+			"hiveio_template_version": resourceTemplateVersion(),
+			"hiveio_template_alias":   resourceTemplateAlias(),
+			"hiveio_template_export":  resourceTemplateExport(),
+			"hiveio_guest_pool":       resourceGuestPool(),
+			"hiveio_pool_assignment":  resourcePoolAssignment(),
+			"hiveio_virtual_machine":  resourceVM(),
+			"hiveio_license":          resourceLicense(),
+			"hiveio_external_guest":   resourceExternalGuest(),
+			"hiveio_user":             resourceUser(),
+			"hiveio_shared_storage":   resourceSharedStorage(),
+			"hiveio_host_network":     resourceHostNetwork(),
+			"hiveio_host_iscsi":       resourceHostIscsi(),
+			"hiveio_gateway_host":     resourceGatewayHost(),
 		},
 
 		ConfigureFunc: providerConfigure,
@@ -170,3 +186,12 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	}
 	return client, err
 }
+
+// writeOnlyString returns the value of a write-only attribute from the raw config
+func writeOnlyString(d *schema.ResourceData, key string) string {
+	value, diags := d.GetRawConfigAt(cty.GetAttrPath(key))
+	if diags.HasError() || !value.IsKnown() || value.IsNull() || !value.Type().Equals(cty.String) {
+		return ""
+	}
+	return value.AsString()
+}
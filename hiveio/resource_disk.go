@@ -1,7 +1,16 @@
 package hiveio
 
 import (
+	"archive/tar"
 	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,12 +23,29 @@ func resourceDisk() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceDiskCreate,
 		ReadContext:   resourceDiskRead,
+		UpdateContext: resourceDiskUpdate,
 		DeleteContext: resourceDiskDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceDiskImport,
+		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			//plan an update to flatten an existing linked clone
+			if d.Id() != "" && d.Get("flatten").(bool) && d.Get("backing_chain.#").(int) > 0 {
+				return d.SetNewComputed("backing_chain")
+			}
+			return nil
+		},
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceDiskV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceDiskStateUpgradeV0,
+			},
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
 		},
 		Schema: map[string]*schema.Schema{
 			"filename": {
@@ -28,17 +54,20 @@ func resourceDisk() *schema.Resource {
 				ForceNew: true,
 			},
 			"storage_pool": {
-				Description: "The storage id for where to store the disk.",
+				Description: "The storage id for where to store the disk. Changing it moves the disk to the new storage pool and updates templates and pools using it. The move is refused while running guests use the disk.",
 				Type:        schema.TypeString,
 				Required:    true,
-				ForceNew:    true,
 			},
 			"size": {
-				Description: "Size of the disk in GB",
+				Description: "Size of the disk in GB. Adopted and imported disks keep their existing size and changes to size are ignored for them.",
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Default:     30,
 				ForceNew:    true,
+				//size only applies when the file is created, and an existing disk may not be a whole number of GB
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return d.Id() != "" && d.Get("adopted").(bool)
+				},
 			},
 			"format": {
 				Description: "File format (qcow2 or raw)",
@@ -60,13 +89,70 @@ func resourceDisk() *schema.Resource {
 				ForceNew:    true,
 			},
 			"src_url": {
-				Description: "HTTP url for a disk to copy into the storage pool.",
+				Description: "HTTP url for a disk to copy into the storage pool. The image is converted to format.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"src_format": {
+				Description: "Format of the source image (qcow2, raw, vmdk, vhdx, vpc, or ova). Detected from the source when not set. OVA archives are extracted and each disk is converted.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if !slices.Contains([]string{"qcow2", "raw", "vmdk", "vhdx", "vpc", "ova"}, val.(string)) {
+						errs = append(errs, fmt.Errorf("%q must be one of qcow2, raw, vmdk, vhdx, vpc, or ova", key))
+					}
+					return
+				},
+			},
+			"ova": {
+				Description: "Suggested template settings from the OVF descriptor when importing an OVA.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"cpu": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"memory": {
+							Description: "memory in MB",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"firmware": {
+							Description: "uefi or bios",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"os_type": {
+							Description: "operating system type from the OVF descriptor",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"networks": {
+							Description: "network names of the interfaces",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"disks": {
+							Description: "filenames of the converted disks in the storage pool, the first is filename",
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
 			},
 			"local_file": {
-				Description: "A local file to upload to the storage pool.",
+				Description: "A local file to upload to the storage pool. The image is converted to format.",
 				Type:        schema.TypeString,
 				Optional:    true,
 				ForceNew:    true,
@@ -90,6 +176,60 @@ func resourceDisk() *schema.Resource {
 				Optional:    true,
 				ForceNew:    true,
 			},
+			"flatten": {
+				Description: "Consolidate the backing chain into a standalone image. Setting this on an existing linked clone flattens it in place. Flattening in place is refused while running guests use the disk, while other disks are backed by it, or when the disk was adopted and allow_delete_adopted is not set.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"backing_chain": {
+				Description: "The backing files of the disk, starting with the direct backing file.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"storage_id": {
+							Description: "storage pool id of the backing file, empty if it is outside of a storage pool",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"filename": {
+							Description: "filename of the backing file",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+						"format": {
+							Description: "format of the backing file",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"on_existing": {
+				Description: "What to do when filename already exists in the storage pool. 'adopt' manages the existing disk but refuses to delete it unless allow_delete_adopted is set, 'adopt_no_delete' manages it and leaves it in place on destroy, and 'error' fails the apply.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "adopt",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if v != "adopt" && v != "adopt_no_delete" && v != "error" {
+						errs = append(errs, fmt.Errorf("%q must be adopt, adopt_no_delete, or error", key))
+					}
+					return
+				},
+			},
+			"allow_delete_adopted": {
+				Description: "Allow destroy to delete a disk that existed before it was managed.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"adopted": {
+				Description: "The disk existed before it was managed and was adopted.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
 			"provider_override": &providerOverride,
 		},
 	}
@@ -118,24 +258,90 @@ func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, m interface
 	}
 	if _, err := storage.DiskInfo(client, filename); err == nil {
 		//disk already exists
-		d.SetId(id + "-" + filename)
+		if d.Get("on_existing").(string) == "error" {
+			return diag.Errorf("disk %s already exists in storage pool %s", filename, storage.Name)
+		}
+		d.Set("adopted", true)
+		d.SetId(id + "/" + filename)
 		return resourceDiskRead(ctx, d, m)
 	}
-	if localFileOk {
-		err = storage.Upload(client, localFile.(string), filename)
+	d.Set("adopted", false)
+	srcFormat := d.Get("src_format").(string)
+	if localFileOk && (srcFormat == "ova" || srcFormat == "" && isOVA(localFile.(string))) {
+		var f *os.File
+		f, err = os.Open(localFile.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer f.Close()
+		err = importOVA(ctx, client, d, f, storage, filename, format)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	} else if localFileOk {
+		//stage the upload and convert it to format like a storage pool source
+		staged := filename + ".import" + path.Ext(localFile.(string))
+		err = storage.Upload(client, localFile.(string), staged)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		defer storage.DeleteFile(client, staged)
+		srcPool, srcPoolOk = id, true
+		srcFilename, srcFileOk = staged, true
+	}
+	if srcURLOk {
+		//the cluster downloads the source next to the disk, then it is converted like a storage pool source
+		staged := filename + ".import"
+		if srcFormat == "ova" || srcFormat == "" && isOVA(srcURL.(string)) {
+			staged += ".ova"
+			srcFormat = "ova"
+		}
+		task, err = storage.CopyURL(client, srcURL.(string), staged)
+		if err == nil {
+			task, err = task.WaitForTaskWithContext(ctx, client, false)
+		}
 		if err != nil {
 			return diag.FromErr(err)
 		}
+		if task.State == "failed" {
+			return diag.Errorf("Failed to download %s: %s", srcURL, task.Message)
+		}
+		defer storage.DeleteFile(client, staged)
+		srcPool, srcPoolOk = id, true
+		srcFilename, srcFileOk = staged, true
 	}
-	if srcPoolOk && srcFileOk {
+	task = nil
+	if d.Get("ova.#").(int) > 0 {
+		//already imported from a local ova
+	} else if srcPoolOk && srcFileOk {
 		var srcStorage *rest.StoragePool
 		srcStorage, err = client.GetStoragePool(srcPool.(string))
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		task, err = srcStorage.ConvertDisk(client, srcFilename.(string), id, filename, format)
-	} else if srcURLOk {
-		task, err = storage.CopyURL(client, srcURL.(string), filename)
+		srcFormat, err = detectSourceFormat(client, srcStorage, srcFilename.(string), srcFormat)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		d.Set("src_format", srcFormat)
+		if srcFormat == "ova" {
+			var resp *http.Response
+			resp, err = srcStorage.DownloadWithContext(ctx, client, srcFilename.(string))
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != 200 {
+				return diag.Errorf("Failed to download %s: %s", srcFilename, resp.Status)
+			}
+			err = importOVA(ctx, client, d, resp.Body, storage, filename, format)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		} else {
+			//qemu-img detects vmdk, vhdx, and vpc sources during the conversion
+			task, err = srcStorage.ConvertDisk(client, srcFilename.(string), id, filename, format)
+		}
 	} else {
 		var backingFile *rest.StorageDisk
 		backingStorage, backingStorageOk := d.GetOk("backing_storage")
@@ -153,16 +359,18 @@ func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, m interface
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if task == nil {
+	if task == nil && d.Get("ova.#").(int) == 0 {
 		return diag.Errorf("Failed to create disk: Task was not returned")
 	}
 
-	task, err = task.WaitForTaskWithContext(ctx, client, false)
-	if err != nil {
-		return diag.FromErr(err)
-	}
-	if task.State == "failed" {
-		return diag.Errorf("Failed to Create disk: %s", task.Message)
+	if task != nil {
+		task, err = task.WaitForTaskWithContext(ctx, client, false)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if task.State == "failed" {
+			return diag.Errorf("Failed to Create disk: %s", task.Message)
+		}
 	}
 	disk, err := storage.DiskInfo(client, filename)
 	if err != nil {
@@ -182,17 +390,184 @@ func resourceDiskCreate(ctx context.Context, d *schema.ResourceData, m interface
 			return diag.Errorf("Failed to resize disk: %s", task.Message)
 		}
 	}
-	d.SetId(id + "-" + filename)
+	d.SetId(id + "/" + filename)
+	if d.Get("flatten").(bool) && disk.BackingFilename != "" {
+		err = flattenDisk(ctx, client, storage, filename, format)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return resourceDiskRead(ctx, d, m)
 }
 
+// resolveBackingFile maps a backing filename from DiskInfo to a storage pool and filename
+func resolveBackingFile(storagePools []rest.StoragePool, storageID, filename, backing string) (string, string) {
+	if !path.IsAbs(backing) {
+		return storageID, path.Join(path.Dir(filename), backing)
+	}
+	for _, pool := range storagePools {
+		if _, after, ok := strings.Cut(backing, "/"+pool.ID+"/"); ok {
+			return pool.ID, after
+		}
+	}
+	return "", backing
+}
+
+// backingChain follows the backing files of a disk
+func backingChain(client *rest.Client, storagePools []rest.StoragePool, storageID, filename string, disk rest.DiskInfo) []map[string]interface{} {
+	chain := []map[string]interface{}{}
+	for disk.BackingFilename != "" && len(chain) < 32 {
+		storageID, filename = resolveBackingFile(storagePools, storageID, filename, disk.BackingFilename)
+		entry := map[string]interface{}{
+			"storage_id": storageID,
+			"filename":   filename,
+		}
+		chain = append(chain, entry)
+		if storageID == "" {
+			break
+		}
+		storage := rest.StoragePool{ID: storageID}
+		next, err := storage.DiskInfo(client, filename)
+		if err != nil {
+			break
+		}
+		entry["format"] = next.Format
+		disk = next
+	}
+	return chain
+}
+
+// flattenDisk converts a linked clone into a standalone image and replaces the original file.
+// The original is kept until the flattened copy is in place.
+func flattenDisk(ctx context.Context, client *rest.Client, storage *rest.StoragePool, filename, format string) error {
+	flattened := filename + ".flatten"
+	original := filename + ".orig"
+	task, err := storage.ConvertDisk(client, filename, storage.ID, flattened, format)
+	if err != nil {
+		return err
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return err
+	}
+	if task.State == "failed" {
+		return fmt.Errorf("failed to flatten disk %s: %s", filename, task.Message)
+	}
+	if err := moveStorageFile(ctx, client, storage.ID, filename, original); err != nil {
+		return fmt.Errorf("failed to set aside disk %s, the flattened copy is %s: %w", filename, flattened, err)
+	}
+	if err := moveStorageFile(ctx, client, storage.ID, flattened, filename); err != nil {
+		//put the original back so the disk is not lost
+		if restoreErr := moveStorageFile(ctx, client, storage.ID, original, filename); restoreErr != nil {
+			return fmt.Errorf("failed to replace disk %s with the flattened copy: %v, and failed to restore it from %s: %w", filename, err, original, restoreErr)
+		}
+		return fmt.Errorf("failed to replace disk %s with the flattened copy %s: %w", filename, flattened, err)
+	}
+	err = storage.DeleteFile(client, original)
+	if err != nil && !strings.Contains(err.Error(), "\"error\": 404") {
+		return fmt.Errorf("disk %s was flattened but %s could not be deleted: %w", filename, original, err)
+	}
+	return nil
+}
+
+// moveStorageFile renames a file within a storage pool
+func moveStorageFile(ctx context.Context, client *rest.Client, storageID, src, dst string) error {
+	task, err := client.MoveFile(storageID, src, storageID, dst)
+	if err != nil {
+		return err
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return err
+	}
+	if task.State == "failed" {
+		return errors.New(task.Message)
+	}
+	return nil
+}
+
+// sharesHosts reports whether two storage pools are mounted on a common host. Pools without hosts are mounted on every host.
+func sharesHosts(a, b rest.StoragePool) bool {
+	if len(a.Hosts) == 0 || len(b.Hosts) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(a.Hosts, func(host string) bool {
+		return slices.Contains(b.Hosts, host)
+	})
+}
+
+// diskDependents returns the guests and disks that use storageID/filename as a backing file.
+// Guests are found from their disk records. Disk files are only looked for in the disk's own storage pool
+// and in guest or template pools mounted on the same hosts, since a clone can only reach a backing file
+// on a pool its host has mounted. Pools that cannot be browsed are returned as warnings.
+func diskDependents(client *rest.Client, storageID, filename string) ([]string, diag.Diagnostics, error) {
+	storagePools, err := client.ListStoragePools("")
+	if err != nil {
+		return nil, nil, err
+	}
+	source := slices.IndexFunc(storagePools, func(pool rest.StoragePool) bool {
+		return pool.ID == storageID
+	})
+	if source < 0 {
+		return nil, nil, fmt.Errorf("storage pool %s not found", storageID)
+	}
+	var dependents []string
+	var diags diag.Diagnostics
+	guests, err := client.ListGuests("")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, guest := range guests {
+		for _, disk := range guest.Disks {
+			if strings.HasSuffix(disk.Backing, "/"+storageID+"/"+path.Clean(filename)) {
+				dependents = append(dependents, "guest "+guest.Name)
+				break
+			}
+		}
+	}
+	for _, pool := range storagePools {
+		if pool.ID != storageID && (!sharesHosts(pool, storagePools[source]) || !slices.Contains(pool.Roles, "guest") && !slices.Contains(pool.Roles, "template")) {
+			continue
+		}
+		files, err := pool.Browse(client, "", true)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("storage pool %s was not checked for disks backed by %s", pool.Name, filename),
+				Detail:   err.Error(),
+			})
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir || !strings.HasSuffix(file.Name, ".qcow2") {
+				continue
+			}
+			name := file.Path
+			if name == "" {
+				name = file.Name
+			}
+			if pool.ID == storageID && path.Clean(name) == path.Clean(filename) {
+				continue
+			}
+			disk, err := pool.DiskInfo(client, name)
+			if err != nil || disk.BackingFilename == "" {
+				continue
+			}
+			backingStorage, backingFilename := resolveBackingFile(storagePools, pool.ID, name, disk.BackingFilename)
+			if backingStorage == storageID && backingFilename == path.Clean(filename) {
+				dependents = append(dependents, pool.ID+"/"+name)
+			}
+		}
+	}
+	return dependents, diags, nil
+}
+
 func resourceDiskRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client, err := getClient(d, m)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	id := d.Get("storage_pool").(string)
-	filename := d.Get("filename").(string)
+	id, filename, _ := strings.Cut(d.Id(), "/")
 	storage, err := client.GetStoragePool(id)
 	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
 		d.SetId("")
@@ -207,11 +582,326 @@ func resourceDiskRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	} else if err != nil {
 		return diag.FromErr(err)
 	}
+	d.Set("storage_pool", id)
+	d.Set("filename", filename)
 	d.Set("format", disk.Format)
+	chain := []map[string]interface{}{}
+	if disk.BackingFilename != "" {
+		storagePools, err := client.ListStoragePools("")
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		chain = backingChain(client, storagePools, id, filename, disk)
+	}
+	if err := d.Set("backing_chain", chain); err != nil {
+		return diag.FromErr(err)
+	}
 	return diag.Diagnostics{}
 }
 
+// resourceDiskV0 is the schema used before the id changed from storage_pool-filename to storage_pool/filename
+func resourceDiskV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"filename":         {Type: schema.TypeString, Required: true},
+			"storage_pool":     {Type: schema.TypeString, Required: true},
+			"size":             {Type: schema.TypeInt, Optional: true},
+			"format":           {Type: schema.TypeString, Optional: true},
+			"src_storage":      {Type: schema.TypeString, Optional: true},
+			"src_filename":     {Type: schema.TypeString, Optional: true},
+			"src_url":          {Type: schema.TypeString, Optional: true},
+			"local_file":       {Type: schema.TypeString, Optional: true},
+			"backing_storage":  {Type: schema.TypeString, Optional: true},
+			"backing_filename": {Type: schema.TypeString, Optional: true},
+			"backing_format":   {Type: schema.TypeString, Optional: true},
+			"provider_override": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     providerOverride.Elem,
+			},
+		},
+	}
+}
+
+func resourceDiskStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	storagePool, _ := rawState["storage_pool"].(string)
+	filename, _ := rawState["filename"].(string)
+	if storagePool != "" && filename != "" {
+		rawState["id"] = storagePool + "/" + filename
+	}
+	return rawState, nil
+}
+
+func resourceDiskImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	storagePool, filename, ok := strings.Cut(d.Id(), "/")
+	if !ok || storagePool == "" || filename == "" {
+		return nil, fmt.Errorf("invalid import id %q, expected storage_pool_id/filename", d.Id())
+	}
+	client, err := getClient(d, m)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := client.GetStoragePool(storagePool)
+	if err != nil {
+		return nil, err
+	}
+	disk, err := storage.DiskInfo(client, filename)
+	if err != nil {
+		return nil, err
+	}
+	d.Set("storage_pool", storagePool)
+	d.Set("filename", filename)
+	d.Set("size", int(disk.VirtualSize/1024/1024/1024))
+	d.Set("backing_format", "qcow2")
+	if disk.BackingFilename != "" {
+		storagePools, err := client.ListStoragePools("")
+		if err != nil {
+			return nil, err
+		}
+		backingStorage, backingFilename := resolveBackingFile(storagePools, storagePool, filename, disk.BackingFilename)
+		d.Set("backing_storage", backingStorage)
+		d.Set("backing_filename", backingFilename)
+		if backingStorage != "" {
+			backing := rest.StoragePool{ID: backingStorage}
+			if info, err := backing.DiskInfo(client, backingFilename); err == nil && info.Format != "" {
+				d.Set("backing_format", info.Format)
+			}
+		}
+	}
+	//an imported disk already existed, so it is protected like an adopted disk
+	d.Set("on_existing", "adopt")
+	d.Set("allow_delete_adopted", false)
+	d.Set("adopted", true)
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceDiskUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if d.Get("flatten").(bool) {
+		client, err := getClient(d, m)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		id, filename, _ := strings.Cut(d.Id(), "/")
+		storage, err := client.GetStoragePool(id)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		disk, err := storage.DiskInfo(client, filename)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if disk.BackingFilename != "" {
+			if d.Get("adopted").(bool) && !d.Get("allow_delete_adopted").(bool) {
+				return diag.Errorf("disk %s was adopted and flattening it replaces the original file, set allow_delete_adopted to flatten it", filename)
+			}
+			//a running guest would keep writing to the file that is replaced
+			guests, err := runningGuestsUsingDisk(client, id, filename)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			if len(guests) > 0 {
+				return diag.Errorf("disk %s is in use by running guests %s, shut them down before flattening it", filename, strings.Join(guests, ", "))
+			}
+			//replacing the file would break linked clones of this disk
+			dependents, warnings, err := diskDependents(client, id, filename)
+			diags = append(diags, warnings...)
+			if err != nil {
+				return append(diags, diag.FromErr(err)...)
+			}
+			if len(dependents) > 0 {
+				return append(diags, diag.Errorf("disk %s is the backing file of %s and cannot be flattened in place", filename, strings.Join(dependents, ", "))...)
+			}
+			err = flattenDisk(ctx, client, storage, filename, disk.Format)
+			if err != nil {
+				return append(diags, diag.FromErr(err)...)
+			}
+		}
+	}
+	if d.HasChange("storage_pool") {
+		oldID, newID := d.GetChange("storage_pool")
+		filename := d.Get("filename").(string)
+		//keep the old storage pool in the state unless the disk was moved
+		if d.Get("adopted").(bool) && !d.Get("allow_delete_adopted").(bool) {
+			d.Set("storage_pool", oldID)
+			return append(diags, diag.Errorf("disk %s was adopted and moving it would delete the original, set allow_delete_adopted to move it", filename)...)
+		}
+		client, err := getClient(d, m)
+		if err != nil {
+			d.Set("storage_pool", oldID)
+			return append(diags, diag.FromErr(err)...)
+		}
+		//linked clones would lose their backing file
+		dependents, warnings, err := diskDependents(client, oldID.(string), filename)
+		diags = append(diags, warnings...)
+		if err != nil {
+			d.Set("storage_pool", oldID)
+			return append(diags, diag.FromErr(err)...)
+		}
+		if len(dependents) > 0 {
+			d.Set("storage_pool", oldID)
+			return append(diags, diag.Errorf("disk %s is the backing file of %s, flatten or delete those disks before moving it", filename, strings.Join(dependents, ", "))...)
+		}
+		moved, err := moveDisk(ctx, client, oldID.(string), newID.(string), filename, d.Get("format").(string))
+		if moved {
+			d.SetId(newID.(string) + "/" + filename)
+		} else {
+			d.Set("storage_pool", oldID)
+		}
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+	}
+	return append(diags, resourceDiskRead(ctx, d, m)...)
+}
+
+// runningGuestsUsingDisk returns the guests that are not powered off and have storageID/filename open
+func runningGuestsUsingDisk(client *rest.Client, storageID, filename string) ([]string, error) {
+	guests, err := client.ListGuests("")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, guest := range guests {
+		if guestPoweredOff(&guest) {
+			continue
+		}
+		for _, disk := range guest.Disks {
+			if disk.StorageID == storageID && path.Clean(disk.Filename) == path.Clean(filename) ||
+				strings.HasSuffix(disk.Backing, "/"+storageID+"/"+path.Clean(filename)) {
+				names = append(names, guest.Name)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// moveDisk copies a disk to another storage pool, points templates and pools at the copy, and then deletes the original.
+// moved reports whether the copy replaced the original, which can be true even when an error is returned.
+func moveDisk(ctx context.Context, client *rest.Client, srcID, dstID, filename, format string) (bool, error) {
+	srcStorage, err := client.GetStoragePool(srcID)
+	if err != nil {
+		return false, err
+	}
+	//running guests would keep using the original file
+	guests, err := runningGuestsUsingDisk(client, srcID, filename)
+	if err != nil {
+		return false, err
+	}
+	if len(guests) > 0 {
+		return false, fmt.Errorf("disk %s is in use by running guests %s, shut them down before moving it", filename, strings.Join(guests, ", "))
+	}
+	task, err := srcStorage.ConvertDisk(client, filename, dstID, filename, format)
+	if err != nil {
+		return false, err
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return false, err
+	}
+	if task.State == "failed" {
+		return false, fmt.Errorf("failed to copy disk %s: %s", filename, task.Message)
+	}
+	//abort removes the copy and leaves the original in place
+	abort := func(err error) (bool, error) {
+		dstStorage := rest.StoragePool{ID: dstID}
+		if deleteErr := dstStorage.DeleteFile(client, filename); deleteErr != nil && !strings.Contains(deleteErr.Error(), "\"error\": 404") {
+			return false, fmt.Errorf("%w, and the copy in storage pool %s could not be deleted: %v", err, dstID, deleteErr)
+		}
+		return false, err
+	}
+
+	//a guest may have started from the original while it was copied
+	guests, err = runningGuestsUsingDisk(client, srcID, filename)
+	if err != nil {
+		return abort(err)
+	}
+	if len(guests) > 0 {
+		return abort(fmt.Errorf("disk %s was not moved because running guests %s started using it during the copy, shut them down and apply again", filename, strings.Join(guests, ", ")))
+	}
+
+	templates, err := client.ListTemplates("")
+	if err != nil {
+		return abort(err)
+	}
+	pools, err := client.ListGuestPools("")
+	if err != nil {
+		return abort(err)
+	}
+	//rollback points the updated templates and pools back at the original
+	var updatedTemplates []rest.Template
+	var updatedPools []rest.Pool
+	setTemplateStorage := func(template rest.Template, from, to string) bool {
+		changed := false
+		for _, disk := range template.Disks {
+			if disk.StorageID == from && disk.Filename == filename {
+				disk.StorageID = to
+				changed = true
+			}
+		}
+		return changed
+	}
+	setPoolStorage := func(pool rest.Pool, from, to string) bool {
+		if pool.GuestProfile == nil {
+			return false
+		}
+		changed := false
+		for _, disk := range pool.GuestProfile.Disks {
+			if disk.StorageID == from && disk.Filename == filename {
+				disk.StorageID = to
+				changed = true
+			}
+		}
+		return changed
+	}
+	rollback := func(err error) (bool, error) {
+		for _, template := range updatedTemplates {
+			setTemplateStorage(template, dstID, srcID)
+			if _, rollbackErr := template.Update(client); rollbackErr != nil {
+				return false, fmt.Errorf("%w, and template %s could not be pointed back at storage pool %s: %v", err, template.Name, srcID, rollbackErr)
+			}
+		}
+		for _, pool := range updatedPools {
+			setPoolStorage(pool, dstID, srcID)
+			if _, rollbackErr := pool.Update(client); rollbackErr != nil {
+				return false, fmt.Errorf("%w, and pool %s could not be pointed back at storage pool %s: %v", err, pool.Name, srcID, rollbackErr)
+			}
+		}
+		return abort(err)
+	}
+	for _, template := range templates {
+		if setTemplateStorage(template, srcID, dstID) {
+			if _, err := template.Update(client); err != nil {
+				return rollback(fmt.Errorf("failed to update template %s: %w", template.Name, err))
+			}
+			updatedTemplates = append(updatedTemplates, template)
+		}
+	}
+	for _, pool := range pools {
+		if setPoolStorage(pool, srcID, dstID) {
+			if _, err := pool.Update(client); err != nil {
+				return rollback(fmt.Errorf("failed to update pool %s: %w", pool.Name, err))
+			}
+			updatedPools = append(updatedPools, pool)
+		}
+	}
+
+	err = srcStorage.DeleteFile(client, filename)
+	if err != nil && !strings.Contains(err.Error(), "\"error\": 404") {
+		return true, fmt.Errorf("disk %s was moved but the original could not be deleted: %w", filename, err)
+	}
+	return true, nil
+}
+
 func resourceDiskDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if d.Get("adopted").(bool) && !d.Get("allow_delete_adopted").(bool) {
+		if d.Get("on_existing").(string) == "adopt_no_delete" {
+			//leave the disk in place and only remove it from the state
+			return diag.Diagnostics{}
+		}
+		return diag.Errorf("disk %s was adopted and will not be deleted, set allow_delete_adopted to delete it or on_existing to adopt_no_delete to leave it in place", d.Get("filename").(string))
+	}
 	client, err := getClient(d, m)
 	if err != nil {
 		return diag.FromErr(err)
@@ -221,12 +911,180 @@ func resourceDiskDelete(ctx context.Context, d *schema.ResourceData, m interface
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	dependents, diags, err := diskDependents(client, id, d.Get("filename").(string))
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	if len(dependents) > 0 {
+		return append(diags, diag.Errorf("disk %s is the backing file of %s, flatten or delete those disks first", d.Get("filename").(string), strings.Join(dependents, ", "))...)
+	}
 	err = storage.DeleteFile(client, d.Get("filename").(string))
-	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
-		return diag.Diagnostics{}
+	if err != nil && !strings.Contains(err.Error(), "\"error\": 404") {
+		return append(diags, diag.FromErr(err)...)
+	}
+	//additional disks converted from an ova
+	for _, disk := range d.Get("ova.0.disks").([]interface{}) {
+		if disk.(string) == d.Get("filename").(string) {
+			continue
+		}
+		err = storage.DeleteFile(client, disk.(string))
+		if err != nil && !strings.Contains(err.Error(), "\"error\": 404") {
+			return append(diags, diag.FromErr(fmt.Errorf("failed to delete ova disk %s: %w", disk, err))...)
+		}
+	}
+	return diags
+}
+
+func isOVA(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".ova")
+}
+
+// detectSourceFormat returns the format of a source image and checks it against the expected format
+func detectSourceFormat(client *rest.Client, storage *rest.StoragePool, filename, expected string) (string, error) {
+	if expected == "ova" || expected == "" && isOVA(filename) {
+		return "ova", nil
 	}
+	info, err := storage.DiskInfo(client, filename)
 	if err != nil {
-		return diag.FromErr(err)
+		return "", err
 	}
-	return diag.Diagnostics{}
+	if expected != "" && info.Format != expected {
+		return "", fmt.Errorf("%s is %s, not %s", filename, info.Format, expected)
+	}
+	return info.Format, nil
+}
+
+type ovfItem struct {
+	ResourceType    int    `xml:"ResourceType"`
+	VirtualQuantity int    `xml:"VirtualQuantity"`
+	AllocationUnits string `xml:"AllocationUnits"`
+	Connection      string `xml:"Connection"`
+}
+
+type ovfEnvelope struct {
+	VirtualSystem struct {
+		OperatingSystemSection struct {
+			OSType      string `xml:"osType,attr"`
+			Description string `xml:"Description"`
+		} `xml:"OperatingSystemSection"`
+		VirtualHardwareSection struct {
+			Items   []ovfItem `xml:"Item"`
+			Configs []struct {
+				Key   string `xml:"key,attr"`
+				Value string `xml:"value,attr"`
+			} `xml:"Config"`
+		} `xml:"VirtualHardwareSection"`
+	} `xml:"VirtualSystem"`
+}
+
+// ovfSettings converts an OVF descriptor to the computed ova settings
+func ovfSettings(envelope *ovfEnvelope) map[string]interface{} {
+	settings := map[string]interface{}{
+		"firmware": "bios",
+		"os_type":  envelope.VirtualSystem.OperatingSystemSection.OSType,
+	}
+	if settings["os_type"] == "" {
+		settings["os_type"] = envelope.VirtualSystem.OperatingSystemSection.Description
+	}
+	networks := []string{}
+	for _, item := range envelope.VirtualSystem.VirtualHardwareSection.Items {
+		switch item.ResourceType {
+		case 3:
+			settings["cpu"] = item.VirtualQuantity
+		case 4:
+			//AllocationUnits is usually "byte * 2^20"
+			memory := item.VirtualQuantity
+			units := strings.ToLower(strings.ReplaceAll(item.AllocationUnits, " ", ""))
+			var exp int
+			if _, err := fmt.Sscanf(units, "byte*2^%d", &exp); err == nil {
+				memory = item.VirtualQuantity << exp >> 20
+			} else if units == "byte" || units == "bytes" {
+				memory = item.VirtualQuantity >> 20
+			} else if units == "kilobytes" {
+				memory = item.VirtualQuantity >> 10
+			} else if units == "gigabytes" {
+				memory = item.VirtualQuantity << 10
+			}
+			settings["memory"] = memory
+		case 10:
+			networks = append(networks, item.Connection)
+		}
+	}
+	for _, config := range envelope.VirtualSystem.VirtualHardwareSection.Configs {
+		if config.Key == "firmware" && config.Value == "efi" {
+			settings["firmware"] = "uefi"
+		}
+	}
+	settings["networks"] = networks
+	return settings
+}
+
+// importOVA extracts the disks from an ova archive, uploads them to storage, and converts them to format
+func importOVA(ctx context.Context, client *rest.Client, d *schema.ResourceData, ova io.Reader, storage *rest.StoragePool, filename, format string) error {
+	settings := map[string]interface{}{}
+	disks := []string{}
+	archive := tar.NewReader(ova)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		switch strings.ToLower(path.Ext(header.Name)) {
+		case ".ovf":
+			var envelope ovfEnvelope
+			if err := xml.NewDecoder(archive).Decode(&envelope); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", header.Name, err)
+			}
+			settings = ovfSettings(&envelope)
+		case ".vmdk", ".vhd", ".vhdx", ".qcow2", ".img":
+			target := filename
+			if len(disks) > 0 {
+				ext := path.Ext(filename)
+				target = fmt.Sprintf("%s-disk%d%s", strings.TrimSuffix(filename, ext), len(disks)+1, ext)
+			}
+			if err := importOVADisk(ctx, client, archive, header.Name, storage, target, format); err != nil {
+				return err
+			}
+			disks = append(disks, target)
+		}
+	}
+	if len(disks) == 0 {
+		return errors.New("no disks found in the ova")
+	}
+	settings["disks"] = disks
+	d.Set("src_format", "ova")
+	return d.Set("ova", []interface{}{settings})
+}
+
+func importOVADisk(ctx context.Context, client *rest.Client, disk io.Reader, name string, storage *rest.StoragePool, target, format string) error {
+	f, err := os.CreateTemp("", "hiveio-ova-*"+path.Ext(name))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, disk)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	staged := target + ".import" + path.Ext(name)
+	err = storage.Upload(client, f.Name(), staged)
+	if err != nil {
+		return err
+	}
+	defer storage.DeleteFile(client, staged)
+	task, err := storage.ConvertDisk(client, staged, storage.ID, target, format)
+	if err != nil {
+		return err
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return err
+	}
+	if task.State == "failed" {
+		return fmt.Errorf("failed to convert %s: %s", name, task.Message)
+	}
+	return nil
 }
@@ -2,11 +2,17 @@ package hiveio
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"log"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hive-io/hive-go-client/rest"
@@ -18,10 +24,18 @@ func resourceGuestPool() *schema.Resource {
 		ReadContext:   resourceGuestPoolRead,
 		UpdateContext: resourceGuestPoolUpdate,
 		DeleteContext: resourceGuestPoolDelete,
+		CustomizeDiff: customdiff.All(
+			customizeDiffBackupTarget,
+			customizeDiffPoolSchedule,
+			customizeDiffHostSelector,
+			customizeDiffCapacity(poolFootprint, "cpu", "memory", "gpu", "density", "schedule", "allowed_hosts", "resolved_hosts", "template", "storage_id"),
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Update: schema.DefaultTimeout(60 * time.Minute),
 			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 
@@ -40,6 +54,80 @@ func resourceGuestPool() *schema.Resource {
 					Type: schema.TypeInt,
 				},
 			},
+			"schedule": {
+				Type:        schema.TypeList,
+				Description: "Time windows that override density. The first matching window is used and density applies outside of every window. The schedule is stored in a pool tag and applied to the pool by terraform apply. The windows are evaluated when the plan is created and apply uses the planned effective_density.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"timezone": {
+							Type:        schema.TypeString,
+							Description: "IANA timezone used to evaluate the windows.",
+							Default:     "UTC",
+							Optional:    true,
+							ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+								if _, err := time.LoadLocation(val.(string)); err != nil {
+									errs = append(errs, fmt.Errorf("%q: %w", key, err))
+								}
+								return
+							},
+						},
+						"window": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"days": {
+										Type:        schema.TypeList,
+										Description: "Days the window starts on (mon, tue, wed, thu, fri, sat, sun). Defaults to every day.",
+										Optional:    true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+											ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+												if _, ok := scheduleDays[val.(string)]; !ok {
+													errs = append(errs, fmt.Errorf("%q must be one of mon, tue, wed, thu, fri, sat, or sun", key))
+												}
+												return
+											},
+										},
+									},
+									"start": {
+										Type:         schema.TypeString,
+										Description:  "Start time of the window in HH:MM format.",
+										Required:     true,
+										ValidateFunc: validateScheduleTime,
+									},
+									"end": {
+										Type:         schema.TypeString,
+										Description:  "End time of the window in HH:MM format. An end before the start continues into the next day.",
+										Required:     true,
+										ValidateFunc: validateScheduleTime,
+									},
+									"density": {
+										Type:     schema.TypeList,
+										Required: true,
+										MinItems: 2,
+										MaxItems: 2,
+										Elem: &schema.Schema{
+											Type: schema.TypeInt,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"effective_density": {
+				Type:        schema.TypeList,
+				Description: "The density currently in force after applying the schedule.",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
 			"cpu": {
 				Type:     schema.TypeInt,
 				Optional: true,
@@ -95,12 +183,62 @@ func resourceGuestPool() *schema.Resource {
 							Required: true,
 						},
 						"frequency": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Description:  "How often to run the backup. Allowed values are 'daily', 'weekly', and 'monthly'.",
+							Required:     true,
+							ValidateFunc: validateBackupFrequency,
 						},
 						"target": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:        schema.TypeString,
+							Description: "The id of a storage pool with the backup role.",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"rollout": {
+				Type:        schema.TypeList,
+				Description: "How existing non-persistent guests are refreshed when the template changes.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"strategy": {
+							Type:        schema.TypeString,
+							Description: "Allowed values are 'immediate' to refresh every guest at once, 'rolling' to refresh guests in batches, and 'on_logoff' to let guests pick up the template when they are recycled.",
+							Default:     "on_logoff",
+							Optional:    true,
+							ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+								v := val.(string)
+								if v != "immediate" && v != "rolling" && v != "on_logoff" {
+									errs = append(errs, fmt.Errorf("%q must be immediate, rolling, or on_logoff", key))
+								}
+								return
+							},
+						},
+						"max_unavailable": {
+							Type:        schema.TypeInt,
+							Description: "Number of guests to refresh in each batch of a rolling update.",
+							Default:     1,
+							Optional:    true,
+							ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+								if val.(int) < 1 {
+									errs = append(errs, fmt.Errorf("%q must be at least 1", key))
+								}
+								return
+							},
+						},
+						"batch_interval": {
+							Type:        schema.TypeInt,
+							Description: "Seconds to wait between batches of a rolling update.",
+							Default:     0,
+							Optional:    true,
+							ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+								if val.(int) < 0 {
+									errs = append(errs, fmt.Errorf("%q must not be negative", key))
+								}
+								return
+							},
 						},
 					},
 				},
@@ -116,16 +254,70 @@ func resourceGuestPool() *schema.Resource {
 				Optional: true,
 			},
 			"allowed_hosts": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"host_selector"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"capacity_check": &capacityCheck,
+			"host_selector": {
+				Type:          schema.TypeList,
+				Description:   "Restrict the pool to hosts with all of these tags. The matching hosts are resolved on every plan. Host tags are read only in this provider and must be set on the hosts outside of terraform.",
+				Optional:      true,
+				ConflictsWith: []string{"allowed_hosts"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"anti_affinity": {
+				Type:        schema.TypeBool,
+				Description: "Spread guests evenly across the allowed hosts by migrating guests after the pool is created or updated. Creating the pool waits for the guests to be built, even when wait_for_build is false.",
+				Default:     false,
+				Optional:    true,
+			},
+			"resolved_hosts": {
+				Type:        schema.TypeList,
+				Description: "The host ids the pool is allowed to run on.",
+				Computed:    true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
 			"wait_for_build": {
-				Type:     schema.TypeBool,
-				Default:  false,
-				Optional: true,
+				Type:        schema.TypeBool,
+				Description: "Wait for the pool guests to be built before returning. Clone failures will fail the apply. Pools with anti_affinity always wait for the build on create so the guests can be spread.",
+				Default:     false,
+				Optional:    true,
+			},
+			"wait_for_build_method": {
+				Type:        schema.TypeString,
+				Description: "How to decide the pool is built. Allowed values are 'tracking' to wait for the pool to reach the tracking state and 'minimumReady' to wait for density[0] guests to be ready.",
+				Default:     "tracking",
+				Optional:    true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if v != "tracking" && v != "minimumReady" {
+						errs = append(errs, fmt.Errorf("%q must be tracking or minimumReady", key))
+					}
+					return
+				},
+			},
+			"guest_count": {
+				Type:        schema.TypeInt,
+				Description: "number of guests in the pool",
+				Computed:    true,
+			},
+			"ready_count": {
+				Type:        schema.TypeInt,
+				Description: "number of guests in the ready state",
+				Computed:    true,
+			},
+			"failed_count": {
+				Type:        schema.TypeInt,
+				Description: "number of guests with an error",
+				Computed:    true,
 			},
 			"broker_default_connection": {
 				Type:     schema.TypeString,
@@ -193,6 +385,227 @@ func resourceGuestPool() *schema.Resource {
 	}
 }
 
+func validateBackupFrequency(val interface{}, key string) (warns []string, errs []error) {
+	v := val.(string)
+	if v != "daily" && v != "weekly" && v != "monthly" {
+		errs = append(errs, fmt.Errorf("%q must be daily, weekly, or monthly", key))
+	}
+	return
+}
+
+// customizeDiffBackupTarget checks that the backup target is a storage pool with the backup role
+func customizeDiffBackupTarget(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	target, ok := d.GetOk("backup.0.target")
+	if !ok || !d.NewValueKnown("backup.0.target") {
+		return nil
+	}
+	client, err := getClient(d, m)
+	if err != nil {
+		return err
+	}
+	storage, err := client.GetStoragePool(target.(string))
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		return fmt.Errorf("backup target storage pool %s does not exist", target)
+	} else if err != nil {
+		return err
+	}
+	if !slices.Contains(storage.Roles, "backup") {
+		return fmt.Errorf("backup target storage pool %s does not have the backup role", storage.Name)
+	}
+	return nil
+}
+
+var scheduleDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func validateScheduleTime(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := time.Parse("15:04", val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be in HH:MM format", key))
+	}
+	return
+}
+
+type poolScheduleWindow struct {
+	Days    []string `json:"days,omitempty"`
+	Start   string   `json:"start"`
+	End     string   `json:"end"`
+	Density []int    `json:"density"`
+}
+
+// poolSchedule is stored in a pool tag since pools do not have a native schedule.
+// Density is the base density used outside of the windows.
+type poolSchedule struct {
+	Timezone string               `json:"timezone"`
+	Density  []int                `json:"density,omitempty"`
+	Windows  []poolScheduleWindow `json:"windows"`
+}
+
+const poolScheduleTagPrefix = "terraform-schedule:"
+
+// tag encodes the schedule as a pool tag
+func (schedule *poolSchedule) tag() (string, error) {
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return "", err
+	}
+	return poolScheduleTagPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// poolScheduleFromTags returns the schedule stored in the pool tags, or nil
+func poolScheduleFromTags(tags []string) *poolSchedule {
+	for _, tag := range tags {
+		encoded, ok := strings.CutPrefix(tag, poolScheduleTagPrefix)
+		if !ok {
+			continue
+		}
+		if encoded == "" {
+			return nil
+		}
+		data, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil
+		}
+		var schedule poolSchedule
+		if err := json.Unmarshal(data, &schedule); err != nil || len(schedule.Windows) == 0 {
+			return nil
+		}
+		return &schedule
+	}
+	return nil
+}
+
+// poolScheduleTags replaces the schedule tag in tags and keeps the other tags.
+// An empty tag list is left out of the pool update and the server keeps the old tags,
+// so a removed schedule leaves an empty schedule tag behind when no other tags remain.
+func poolScheduleTags(tags []string, schedule *poolSchedule) ([]string, error) {
+	result := slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+		return strings.HasPrefix(tag, poolScheduleTagPrefix)
+	})
+	if schedule != nil {
+		tag, err := schedule.tag()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tag)
+	} else if len(result) == 0 && len(tags) > 0 {
+		result = append(result, poolScheduleTagPrefix)
+	}
+	return result, nil
+}
+
+func poolScheduleFromList(list []interface{}) *poolSchedule {
+	if len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	settings := list[0].(map[string]interface{})
+	schedule := poolSchedule{Timezone: settings["timezone"].(string)}
+	for _, w := range settings["window"].([]interface{}) {
+		w := w.(map[string]interface{})
+		window := poolScheduleWindow{
+			Start: w["start"].(string),
+			End:   w["end"].(string),
+		}
+		for _, day := range w["days"].([]interface{}) {
+			window.Days = append(window.Days, day.(string))
+		}
+		for _, density := range w["density"].([]interface{}) {
+			window.Density = append(window.Density, density.(int))
+		}
+		schedule.Windows = append(schedule.Windows, window)
+	}
+	return &schedule
+}
+
+func (schedule *poolSchedule) toList() []interface{} {
+	windows := make([]interface{}, len(schedule.Windows))
+	for i, window := range schedule.Windows {
+		windows[i] = map[string]interface{}{
+			"days":    window.Days,
+			"start":   window.Start,
+			"end":     window.End,
+			"density": window.Density,
+		}
+	}
+	return []interface{}{map[string]interface{}{
+		"timezone": schedule.Timezone,
+		"window":   windows,
+	}}
+}
+
+// density returns the density of the first window containing now or base when no window matches
+func (schedule *poolSchedule) density(base []int, now time.Time) ([]int, error) {
+	if schedule == nil {
+		return base, nil
+	}
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(loc)
+	minutes := now.Hour()*60 + now.Minute()
+	for _, window := range schedule.Windows {
+		start, err := time.Parse("15:04", window.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.Parse("15:04", window.End)
+		if err != nil {
+			return nil, err
+		}
+		startMinutes := start.Hour()*60 + start.Minute()
+		endMinutes := end.Hour()*60 + end.Minute()
+		onDay := func(day time.Weekday) bool {
+			if len(window.Days) == 0 {
+				return true
+			}
+			for _, d := range window.Days {
+				if scheduleDays[d] == day {
+					return true
+				}
+			}
+			return false
+		}
+		if startMinutes < endMinutes {
+			if minutes >= startMinutes && minutes < endMinutes && onDay(now.Weekday()) {
+				return window.Density, nil
+			}
+		} else {
+			//window wraps past midnight
+			if minutes >= startMinutes && onDay(now.Weekday()) {
+				return window.Density, nil
+			}
+			if minutes < endMinutes && onDay(now.AddDate(0, 0, -1).Weekday()) {
+				return window.Density, nil
+			}
+		}
+	}
+	return base, nil
+}
+
+// customizeDiffPoolSchedule plans an update when the scheduled density differs from the applied density
+func customizeDiffPoolSchedule(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("schedule") || !d.NewValueKnown("density") {
+		return nil
+	}
+	schedule := poolScheduleFromList(d.Get("schedule").([]interface{}))
+	density, err := schedule.density([]int{d.Get("density.0").(int), d.Get("density.1").(int)}, time.Now())
+	if err != nil {
+		return err
+	}
+	current := d.Get("effective_density").([]interface{})
+	if len(current) != 2 || current[0].(int) != density[0] || current[1].(int) != density[1] {
+		return d.SetNew("effective_density", density)
+	}
+	return nil
+}
+
 func poolFromResource(d *schema.ResourceData) *rest.Pool {
 	pool := rest.Pool{
 		Name:        d.Get("name").(string),
@@ -205,6 +618,15 @@ func poolFromResource(d *schema.ResourceData) *rest.Pool {
 		Density:     []int{d.Get("density.0").(int), d.Get("density.1").(int)},
 	}
 
+	if schedule := poolScheduleFromList(d.Get("schedule").([]interface{})); schedule != nil {
+		//apply the density shown in the plan
+		if density := d.Get("effective_density").([]interface{}); len(density) == 2 {
+			pool.Density = []int{density[0].(int), density[1].(int)}
+		} else if density, err := schedule.density(pool.Density, time.Now()); err == nil {
+			pool.Density = density
+		}
+	}
+
 	guestProfile := rest.PoolGuestProfile{
 		Persistent:   d.Get("persistent").(bool),
 		TemplateName: d.Get("template").(string),
@@ -283,6 +705,12 @@ func resourceGuestPoolCreate(ctx context.Context, d *schema.ResourceData, m inte
 		return diag.FromErr(err)
 	}
 	pool := poolFromResource(d)
+	if tags := hostSelectorFromResource(d); tags != nil {
+		pool.PoolAffinity.AllowedHostIDs, err = hostsWithTags(client, tags)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	template, err := client.GetTemplate(pool.GuestProfile.TemplateName)
 	if err != nil {
@@ -296,6 +724,14 @@ func resourceGuestPoolCreate(ctx context.Context, d *schema.ResourceData, m inte
 	if len(pool.GuestProfile.Mem) != 2 {
 		pool.GuestProfile.Mem = []int{template.Mem, template.Mem}
 	}
+	schedule := poolScheduleFromList(d.Get("schedule").([]interface{}))
+	if schedule != nil {
+		schedule.Density = []int{d.Get("density.0").(int), d.Get("density.1").(int)}
+	}
+	pool.Tags, err = poolScheduleTags(nil, schedule)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	_, err = pool.Create(client)
 	if err != nil {
@@ -305,13 +741,414 @@ func resourceGuestPoolCreate(ctx context.Context, d *schema.ResourceData, m inte
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if d.Get("wait_for_build").(bool) {
-		pool.WaitForPoolWithContext(ctx, client, "tracking", 60*time.Minute)
-	}
 	d.SetId(pool.ID)
+	//guests can only be spread after they are built
+	antiAffinity := d.Get("anti_affinity").(bool)
+	if d.Get("wait_for_build").(bool) || antiAffinity {
+		err = waitForPoolBuild(ctx, client, pool, d.Get("wait_for_build_method").(string), d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if antiAffinity {
+		err = spreadPoolGuests(ctx, client, pool, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return resourceGuestPoolRead(ctx, d, m)
 }
 
+// hostsWithTags returns the ids of the hosts that have every tag in tags
+func hostsWithTags(client *rest.Client, tags []string) ([]string, error) {
+	hosts, err := client.ListHosts("")
+	if err != nil {
+		return nil, err
+	}
+	hostIDs := []string{}
+	for _, host := range hosts {
+		match := true
+		for _, tag := range tags {
+			if !slices.Contains(host.Tags, tag) {
+				match = false
+				break
+			}
+		}
+		if match {
+			hostIDs = append(hostIDs, host.Hostid)
+		}
+	}
+	slices.Sort(hostIDs)
+	return hostIDs, nil
+}
+
+func hostSelectorFromResource(d resourceGetter) []string {
+	selector, ok := d.GetOk("host_selector")
+	if !ok {
+		return nil
+	}
+	tags := make([]string, len(selector.([]interface{})))
+	for i, tag := range selector.([]interface{}) {
+		tags[i] = tag.(string)
+	}
+	return tags
+}
+
+// customizeDiffHostSelector plans an update when the hosts matching host_selector change
+func customizeDiffHostSelector(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("host_selector") {
+		return nil
+	}
+	tags := hostSelectorFromResource(d)
+	if tags == nil {
+		if d.HasChange("allowed_hosts") {
+			return d.SetNewComputed("resolved_hosts")
+		}
+		return nil
+	}
+	client, err := getClient(d, m)
+	if err != nil {
+		return err
+	}
+	hostIDs, err := hostsWithTags(client, tags)
+	if err != nil {
+		return err
+	}
+	if len(hostIDs) == 0 {
+		return fmt.Errorf("no hosts match host_selector %v", tags)
+	}
+	current := d.Get("resolved_hosts").([]interface{})
+	if len(current) != len(hostIDs) {
+		return d.SetNew("resolved_hosts", hostIDs)
+	}
+	for i, hostID := range hostIDs {
+		if current[i].(string) != hostID {
+			return d.SetNew("resolved_hosts", hostIDs)
+		}
+	}
+	return nil
+}
+
+// spreadPoolGuests migrates guests from the busiest host to the least busy host until the pool is balanced
+func spreadPoolGuests(ctx context.Context, client *rest.Client, pool *rest.Pool, timeout time.Duration) error {
+	hostIDs := pool.PoolAffinity.AllowedHostIDs
+	if len(hostIDs) == 0 {
+		hosts, err := client.ListHosts("")
+		if err != nil {
+			return err
+		}
+		for _, host := range hosts {
+			if host.State == "available" && host.Appliance.Role != "gateway" {
+				hostIDs = append(hostIDs, host.Hostid)
+			}
+		}
+	}
+	if len(hostIDs) < 2 {
+		return nil
+	}
+	guests, err := client.ListGuests("poolId=" + url.QueryEscape(pool.ID))
+	if err != nil {
+		return err
+	}
+	byHost := map[string][]rest.Guest{}
+	for _, hostID := range hostIDs {
+		byHost[hostID] = []rest.Guest{}
+	}
+	for _, guest := range guests {
+		if _, ok := byHost[guest.Hostid]; ok && rest.IsGuestReady(guest) {
+			byHost[guest.Hostid] = append(byHost[guest.Hostid], guest)
+		}
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		busiest, idlest := hostIDs[0], hostIDs[0]
+		for _, hostID := range hostIDs {
+			if len(byHost[hostID]) > len(byHost[busiest]) {
+				busiest = hostID
+			}
+			if len(byHost[hostID]) < len(byHost[idlest]) {
+				idlest = hostID
+			}
+		}
+		if len(byHost[busiest])-len(byHost[idlest]) <= 1 {
+			return nil
+		}
+		guest := byHost[busiest][len(byHost[busiest])-1]
+		if err := guest.Migrate(client, idlest); err != nil {
+			return fmt.Errorf("failed to migrate guest %s: %w", guest.Name, err)
+		}
+		err = retry.RetryContext(ctx, time.Until(deadline), func() *retry.RetryError {
+			current, err := client.GetGuest(guest.Name)
+			if err != nil {
+				return retry.NonRetryableError(err)
+			}
+			if current.Hostid == idlest && rest.IsGuestReady(*current) {
+				return nil
+			}
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(fmt.Errorf("waiting for guest %s to migrate", guest.Name))
+		})
+		if err != nil {
+			return err
+		}
+		byHost[busiest] = byHost[busiest][:len(byHost[busiest])-1]
+		byHost[idlest] = append(byHost[idlest], guest)
+	}
+}
+
+var capacityCheck = schema.Schema{
+	Type:        schema.TypeString,
+	Description: "Check at plan time that the allowed hosts have enough memory, cpu, gpu, and clone density for the guests, and that the storage pools exist, are enabled, and are attached to the allowed hosts. Allowed values are 'off', 'warn' to only log the problems to the provider log, and 'error' to fail the plan.",
+	Default:     "error",
+	Optional:    true,
+	ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+		v := val.(string)
+		if v != "off" && v != "warn" && v != "error" {
+			errs = append(errs, fmt.Errorf("%q must be off, warn, or error", key))
+		}
+		return
+	},
+}
+
+// guestFootprint is what a guest pool or virtual machine needs from its hosts
+type guestFootprint struct {
+	count      int
+	cpu        int
+	memory     int
+	gpu        bool
+	hostIDs    []string
+	storageIDs []string
+}
+
+// listFromDiff returns the strings in a list attribute
+func listFromDiff(d *schema.ResourceDiff, key string) []string {
+	var values []string
+	if list, ok := d.GetOk(key); ok {
+		for _, value := range list.([]interface{}) {
+			values = append(values, value.(string))
+		}
+	}
+	return values
+}
+
+// poolFootprint is the footprint of a guest pool at its largest scheduled density
+func poolFootprint(d *schema.ResourceDiff, client *rest.Client) (*guestFootprint, error) {
+	footprint := guestFootprint{
+		count:   d.Get("density.1").(int),
+		cpu:     d.Get("cpu").(int),
+		memory:  d.Get("memory").(int),
+		gpu:     d.Get("gpu").(bool),
+		hostIDs: listFromDiff(d, "resolved_hosts"),
+	}
+	if schedule := poolScheduleFromList(d.Get("schedule").([]interface{})); schedule != nil {
+		for _, window := range schedule.Windows {
+			footprint.count = max(footprint.count, window.Density[1])
+		}
+	}
+	//the template may be created in the same apply, so only use it when it exists
+	if footprint.cpu == 0 || footprint.memory == 0 {
+		if template, err := client.GetTemplate(d.Get("template").(string)); err == nil {
+			footprint.cpu = max(footprint.cpu, template.Vcpu)
+			footprint.memory = max(footprint.memory, template.Mem)
+		}
+	}
+	if len(footprint.hostIDs) == 0 {
+		footprint.hostIDs = listFromDiff(d, "allowed_hosts")
+	}
+	//disk and ram are host local storage rather than storage pools
+	if storageID := d.Get("storage_id").(string); storageID != "disk" && storageID != "ram" {
+		footprint.storageIDs = append(footprint.storageIDs, storageID)
+	}
+	return &footprint, nil
+}
+
+// vmFootprint is the footprint of a single virtual machine and its disks
+func vmFootprint(d *schema.ResourceDiff, client *rest.Client) (*guestFootprint, error) {
+	footprint := guestFootprint{
+		count:   1,
+		cpu:     d.Get("cpu").(int),
+		memory:  d.Get("memory").(int),
+		gpu:     d.Get("gpu").(bool),
+		hostIDs: listFromDiff(d, "allowed_hosts"),
+	}
+	for _, disk := range d.Get("disk").([]interface{}) {
+		storageID := disk.(map[string]interface{})["storage_id"].(string)
+		if storageID != "" && !slices.Contains(footprint.storageIDs, storageID) {
+			footprint.storageIDs = append(footprint.storageIDs, storageID)
+		}
+	}
+	return &footprint, nil
+}
+
+// customizeDiffCapacity compares the footprint of the guests against the allowed hosts and storage pools.
+// keys are the attributes the footprint is computed from.
+func customizeDiffCapacity(footprintFunc func(*schema.ResourceDiff, *rest.Client) (*guestFootprint, error), keys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		mode := d.Get("capacity_check").(string)
+		if mode == "off" {
+			return nil
+		}
+		if d.Id() != "" && !d.HasChanges(keys...) {
+			return nil
+		}
+		for _, key := range keys {
+			if _, ok := d.GetOk(key); ok && !d.NewValueKnown(key) {
+				return nil
+			}
+		}
+		client, err := getClient(d, m)
+		if err != nil {
+			return err
+		}
+		footprint, err := footprintFunc(d, client)
+		if err != nil {
+			return err
+		}
+		problems, err := capacityProblems(client, footprint, d.Id())
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			return nil
+		}
+		if mode == "error" {
+			return fmt.Errorf("capacity check failed: %s. Set capacity_check to warn or off to skip this check", strings.Join(problems, "; "))
+		}
+		for _, problem := range problems {
+			log.Printf("[WARN] capacity check: %s", problem)
+		}
+		return nil
+	}
+}
+
+// capacityProblems lists the reasons footprint does not fit. Guests of poolID are not counted as used.
+func capacityProblems(client *rest.Client, footprint *guestFootprint, poolID string) ([]string, error) {
+	hosts, err := client.ListHosts("")
+	if err != nil {
+		return nil, err
+	}
+	guests, err := client.ListGuests("")
+	if err != nil {
+		return nil, err
+	}
+
+	//memory is in MB, host TotalPhysicalMemory is in bytes
+	var totalMemory, usedMemory, maxThreads, cloneDensity, gpuHosts int
+	var availableHosts []string
+	unlimitedDensity := false
+	for _, host := range hosts {
+		if host.State != "available" || host.Appliance.Role == "gateway" {
+			continue
+		}
+		if len(footprint.hostIDs) > 0 && !slices.Contains(footprint.hostIDs, host.Hostid) {
+			continue
+		}
+		availableHosts = append(availableHosts, host.Hostid)
+		totalMemory += host.Hardware.TotalPhysicalMemory / 1024 / 1024
+		threads := 0
+		for _, processor := range host.Hardware.Processor {
+			threads += processor.Threads
+		}
+		maxThreads = max(maxThreads, threads)
+		if host.Appliance.MaxCloneDensity > 0 {
+			cloneDensity += host.Appliance.MaxCloneDensity
+		} else {
+			unlimitedDensity = true
+		}
+		if len(host.Hardware.VideoCards) > 0 {
+			gpuHosts++
+		}
+		for _, guest := range guests {
+			if guest.Hostid == host.Hostid && (poolID == "" || guest.PoolID != poolID) {
+				usedMemory += guest.Memory
+			}
+		}
+	}
+
+	var problems []string
+	if totalMemory == 0 {
+		problems = append(problems, "no available hosts can run the guests")
+	} else {
+		if free := totalMemory - usedMemory; footprint.memory*footprint.count > free {
+			problems = append(problems, fmt.Sprintf("%d guests with %d MB need %d MB of memory but the hosts have %d MB free", footprint.count, footprint.memory, footprint.memory*footprint.count, free))
+		}
+		if maxThreads > 0 && footprint.cpu > maxThreads {
+			problems = append(problems, fmt.Sprintf("guests need %d cpus but the largest host has %d threads", footprint.cpu, maxThreads))
+		}
+		if !unlimitedDensity && footprint.count > cloneDensity {
+			problems = append(problems, fmt.Sprintf("%d guests exceed the combined max_clone_density of %d for the hosts", footprint.count, cloneDensity))
+		}
+		if footprint.gpu && gpuHosts == 0 {
+			problems = append(problems, "gpu is enabled but none of the hosts have a video card")
+		}
+	}
+
+	//the storage api does not report free space, so only check that the guests can reach their storage
+	for _, storageID := range footprint.storageIDs {
+		storage, err := client.GetStoragePool(storageID)
+		if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+			problems = append(problems, fmt.Sprintf("storage pool %s does not exist", storageID))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if storage.Disabled {
+			problems = append(problems, fmt.Sprintf("storage pool %s is disabled", storage.Name))
+		} else if len(storage.Hosts) > 0 && len(availableHosts) > 0 && !slices.ContainsFunc(availableHosts, func(hostID string) bool {
+			return slices.Contains(storage.Hosts, hostID)
+		}) {
+			problems = append(problems, fmt.Sprintf("storage pool %s is not attached to any of the allowed hosts", storage.Name))
+		}
+	}
+	return problems, nil
+}
+
+func poolGuestFailed(guest rest.Guest) bool {
+	return guest.GuestState == "failed" || (guest.Error != nil && guest.Error.Message != "")
+}
+
+// waitForPoolBuild polls the pool guests until the pool is built and returns an error listing any failed clones
+func waitForPoolBuild(ctx context.Context, client *rest.Client, pool *rest.Pool, method string, timeout time.Duration) error {
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		guests, err := client.ListGuests("poolId=" + url.QueryEscape(pool.ID))
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		ready := 0
+		var failures []string
+		for _, guest := range guests {
+			if poolGuestFailed(guest) {
+				msg := guest.GuestState
+				if guest.Error != nil && guest.Error.Message != "" {
+					msg = guest.Error.Message
+				}
+				failures = append(failures, fmt.Sprintf("%s: %s", guest.Name, msg))
+			} else if rest.IsGuestReady(guest) {
+				ready++
+			}
+		}
+		if len(failures) > 0 {
+			return retry.NonRetryableError(fmt.Errorf("failed to build pool %s:\n%s", pool.Name, strings.Join(failures, "\n")))
+		}
+		switch method {
+		case "minimumReady":
+			if ready >= pool.Density[0] {
+				return nil
+			}
+		default:
+			current, err := client.GetPool(pool.ID)
+			if err != nil {
+				return retry.NonRetryableError(err)
+			}
+			if current.State == "tracking" {
+				return nil
+			}
+		}
+		time.Sleep(5 * time.Second)
+		return retry.RetryableError(fmt.Errorf("building pool %s: %d of %d guests ready", pool.Name, ready, len(guests)))
+	})
+}
+
 func resourceGuestPoolRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client, err := getClient(d, m)
 	if err != nil {
@@ -336,7 +1173,19 @@ func resourceGuestPoolRead(ctx context.Context, d *schema.ResourceData, m interf
 	d.Set("seed", pool.Seed)
 	d.Set("storage_type", pool.StorageType)
 	d.Set("storage_id", pool.StorageID)
-	d.Set("density", pool.Density)
+	//effective_density is the density on the pool, a change made outside of terraform shows up in the next plan
+	d.Set("effective_density", pool.Density)
+	if schedule := poolScheduleFromTags(pool.Tags); schedule != nil {
+		d.Set("schedule", schedule.toList())
+		if len(schedule.Density) == 2 {
+			d.Set("density", schedule.Density)
+		} else {
+			d.Set("density", pool.Density)
+		}
+	} else {
+		d.Set("schedule", nil)
+		d.Set("density", pool.Density)
+	}
 	if pool.GuestProfile.CloudInit != nil {
 		d.Set("cloudinit_enabled", pool.GuestProfile.CloudInit.Enabled)
 		d.Set("cloudinit_userdata", pool.GuestProfile.CloudInit.UserData)
@@ -350,8 +1199,31 @@ func resourceGuestPoolRead(ctx context.Context, d *schema.ResourceData, m interf
 		}})
 	}
 	if pool.PoolAffinity != nil && len(pool.PoolAffinity.AllowedHostIDs) > 0 {
-		d.Set("allowed_hosts", pool.PoolAffinity.AllowedHostIDs)
+		hostIDs := slices.Clone(pool.PoolAffinity.AllowedHostIDs)
+		slices.Sort(hostIDs)
+		d.Set("resolved_hosts", hostIDs)
+		if _, ok := d.GetOk("host_selector"); !ok {
+			d.Set("allowed_hosts", pool.PoolAffinity.AllowedHostIDs)
+		}
+	} else {
+		d.Set("resolved_hosts", []string{})
+	}
+
+	guests, err := client.ListGuests("poolId=" + url.QueryEscape(pool.ID))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	ready, failed := 0, 0
+	for _, guest := range guests {
+		if poolGuestFailed(guest) {
+			failed++
+		} else if rest.IsGuestReady(guest) {
+			ready++
+		}
 	}
+	d.Set("guest_count", len(guests))
+	d.Set("ready_count", ready)
+	d.Set("failed_count", failed)
 
 	if pool.GuestProfile.BrokerOptions != nil {
 		d.Set("broker_default_connection", pool.GuestProfile.BrokerOptions.DefaultConnection)
@@ -384,6 +1256,12 @@ func resourceGuestPoolUpdate(ctx context.Context, d *schema.ResourceData, m inte
 		return diag.FromErr(err)
 	}
 	pool := poolFromResource(d)
+	if tags := hostSelectorFromResource(d); tags != nil {
+		pool.PoolAffinity.AllowedHostIDs, err = hostsWithTags(client, tags)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	template, err := client.GetTemplate(pool.GuestProfile.TemplateName)
 	if err != nil {
@@ -397,13 +1275,96 @@ func resourceGuestPoolUpdate(ctx context.Context, d *schema.ResourceData, m inte
 	if len(pool.GuestProfile.Mem) != 2 {
 		pool.GuestProfile.Mem = []int{template.Mem, template.Mem}
 	}
+	current, err := client.GetPool(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	schedule := poolScheduleFromList(d.Get("schedule").([]interface{}))
+	if schedule != nil {
+		schedule.Density = []int{d.Get("density.0").(int), d.Get("density.1").(int)}
+	}
+	//the update replaces the pool record, keep the fields managed outside of this resource
+	pool.Description = current.Description
+	pool.Assignment = current.Assignment
+	pool.Tags, err = poolScheduleTags(current.Tags, schedule)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	_, err = pool.Update(client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if d.HasChange("template") && !pool.GuestProfile.Persistent {
+		switch d.Get("rollout.0.strategy").(string) {
+		case "immediate":
+			err = pool.Refresh(client)
+		case "rolling":
+			err = rollingRefreshPool(ctx, client, pool, d.Get("rollout.0.max_unavailable").(int), time.Duration(d.Get("rollout.0.batch_interval").(int))*time.Second, d.Timeout(schema.TimeoutUpdate))
+		}
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if d.Get("anti_affinity").(bool) && d.HasChanges("anti_affinity", "allowed_hosts", "resolved_hosts", "template", "density") {
+		err = spreadPoolGuests(ctx, client, pool, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return resourceGuestPoolRead(ctx, d, m)
 }
 
+// rollingRefreshPool refreshes the pool guests in batches and stops at the first batch with a failed guest
+func rollingRefreshPool(ctx context.Context, client *rest.Client, pool *rest.Pool, batchSize int, interval time.Duration, timeout time.Duration) error {
+	guests, err := client.ListGuests("poolId=" + url.QueryEscape(pool.ID))
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for start := 0; start < len(guests); start += batchSize {
+		batch := guests[start:min(start+batchSize, len(guests))]
+		for _, guest := range batch {
+			if err := guest.Refresh(client); err != nil {
+				return fmt.Errorf("failed to refresh guest %s: %w", guest.Name, err)
+			}
+		}
+		for _, guest := range batch {
+			err = retry.RetryContext(ctx, time.Until(deadline), func() *retry.RetryError {
+				current, err := client.GetGuest(guest.Name)
+				if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+					time.Sleep(5 * time.Second)
+					return retry.RetryableError(fmt.Errorf("waiting for guest %s", guest.Name))
+				} else if err != nil {
+					return retry.NonRetryableError(err)
+				}
+				if poolGuestFailed(*current) {
+					msg := current.GuestState
+					if current.Error != nil && current.Error.Message != "" {
+						msg = current.Error.Message
+					}
+					return retry.NonRetryableError(fmt.Errorf("guest %s failed to refresh: %s", current.Name, msg))
+				}
+				if current.TemplateName == pool.GuestProfile.TemplateName && rest.IsGuestReady(*current) {
+					return nil
+				}
+				time.Sleep(5 * time.Second)
+				return retry.RetryableError(fmt.Errorf("waiting for guest %s to be ready", guest.Name))
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if start+batchSize < len(guests) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+	}
+	return nil
+}
+
 func resourceGuestPoolDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client, err := getClient(d, m)
 	if err != nil {
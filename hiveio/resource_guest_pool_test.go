@@ -0,0 +1,50 @@
+package hiveio
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPoolScheduleTagsClear(t *testing.T) {
+	schedule := &poolSchedule{
+		Timezone: "UTC",
+		Density:  []int{1, 4},
+		Windows: []poolScheduleWindow{
+			{Start: "08:00", End: "18:00", Density: []int{4, 10}},
+		},
+	}
+	tags, err := poolScheduleTags(nil, schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poolScheduleFromTags(tags) == nil {
+		t.Fatalf("expected a schedule in %v", tags)
+	}
+
+	cleared, err := poolScheduleTags(tags, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cleared) == 0 {
+		t.Fatal("clearing the only tag must not produce an empty tag list")
+	}
+	if schedule := poolScheduleFromTags(cleared); schedule != nil {
+		t.Fatalf("expected no schedule after clearing, got %+v", schedule)
+	}
+
+	cleared, err = poolScheduleTags(append([]string{"prod"}, tags...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cleared, []string{"prod"}) {
+		t.Fatalf("expected only the other tags to remain, got %v", cleared)
+	}
+
+	untagged, err := poolScheduleTags(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(untagged) != 0 {
+		t.Fatalf("expected no tags for a pool without a schedule, got %v", untagged)
+	}
+}
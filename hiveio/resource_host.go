@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -85,6 +86,9 @@ func resourceHost() *schema.Resource {
 				Description: "set the ntp servers for the host as a comma separated list",
 				Optional:    true,
 				Computed:    true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return slices.Equal(ntpServerList(old), ntpServerList(new))
+				},
 			},
 			"state": {
 				Type:        schema.TypeString,
@@ -99,13 +103,143 @@ func resourceHost() *schema.Resource {
 					return
 				},
 			},
+			"tags": {
+				Type:        schema.TypeList,
+				Description: "tags assigned to the host, used by the host_selector of guest pools. Tags are read only and must be set on the host outside of terraform",
+				Computed:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"existing_host": {
 				Type:     schema.TypeBool,
 				Computed: true,
 			},
 			"provider_override": &providerOverride,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+	}
+}
+
+// retryableHostError reports whether an api error is a connection error or a server error
+func retryableHostError(err error) bool {
+	var code int
+	if _, scanErr := fmt.Sscanf(err.Error(), "{\"error\": %d", &code); scanErr != nil {
+		//not an api response, such as a refused connection
+		return true
+	}
+	return code >= 500
+}
+
+// ntpServerList normalizes a comma or space separated list of ntp servers
+func ntpServerList(servers string) []string {
+	return strings.FieldsFunc(servers, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// waitForHost polls the host until ready returns true
+func waitForHost(ctx context.Context, client *rest.Client, hostid string, timeout time.Duration, desc string, ready func(host *rest.Host) bool) (rest.Host, error) {
+	var host rest.Host
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		var err error
+		host, err = client.GetHost(hostid)
+		if err != nil && retryableHostError(err) {
+			//the api can be unavailable while services restart
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(err)
+		} else if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		if !ready(&host) {
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(fmt.Errorf("waiting for host %s to %s, host is %s", host.Hostname, desc, host.State))
+		}
+		return nil
+	})
+	return host, err
+}
+
+// setHostGatewayMode changes the gateway mode and waits for the host to switch roles
+func setHostGatewayMode(ctx context.Context, client *rest.Client, host *rest.Host, gatewayOnly bool, timeout time.Duration) (rest.Host, error) {
+	if err := host.ChangeGatewayMode(client, gatewayOnly); err != nil {
+		return *host, err
+	}
+	return waitForHost(ctx, client, host.Hostid, timeout, "change gateway mode", func(host *rest.Host) bool {
+		if gatewayOnly {
+			return host.Appliance.Role == "gateway"
+		}
+		return host.Appliance.Role != "gateway" && (host.State == "available" || host.State == "maintenance")
+	})
+}
+
+// setHostState sets the host state and waits until the host reports it
+func setHostState(ctx context.Context, client *rest.Client, host *rest.Host, state string, timeout time.Duration) (rest.Host, error) {
+	task, err := host.SetState(client, state)
+	if err != nil {
+		return *host, err
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return *host, err
+	}
+	if task.State == "failed" {
+		return *host, fmt.Errorf("Failed to set host state: %s", task.Message)
+	}
+	//services might still be restarting after the task finishes
+	return waitForHost(ctx, client, host.Hostid, timeout, "enter "+state, func(h *rest.Host) bool {
+		current, err := h.GetState(client)
+		return err == nil && current == state && h.State == state
+	})
+}
+
+// updateHostAppliance applies the appliance settings and waits for the host to report them
+func updateHostAppliance(ctx context.Context, d *schema.ResourceData, client *rest.Client, host *rest.Host, timeout time.Duration) error {
+	updateAppliance := false
+	if logLevel, ok := d.Get("log_level").(string); ok {
+		if logLevel != "" && host.Appliance.Loglevel != logLevel {
+			updateAppliance = true
+			host.Appliance.Loglevel = logLevel
+		}
+	}
+	if mcd, ok := d.Get("max_clone_density").(int); ok {
+		if mcd != 0 && host.Appliance.MaxCloneDensity != mcd {
+			updateAppliance = true
+			host.Appliance.MaxCloneDensity = mcd
+		}
+	}
+	if ntpServers, ok := d.Get("ntp_servers").(string); ok {
+		if !slices.Equal(ntpServerList(host.Appliance.Ntp), ntpServerList(ntpServers)) {
+			updateAppliance = true
+			host.Appliance.Ntp = ntpServers
+		}
+	}
+	if timezone, ok := d.Get("timezone").(string); ok {
+		if host.Appliance.Timezone != timezone {
+			updateAppliance = true
+			host.Appliance.Timezone = timezone
+		}
+	}
+	if !updateAppliance {
+		return nil
 	}
+	_, err := host.UpdateAppliance(client)
+	if err != nil {
+		return err
+	}
+	//the response does not include the configure task, wait for the settings to be applied
+	want := host.Appliance
+	_, err = waitForHost(ctx, client, host.Hostid, timeout, "apply appliance settings", func(h *rest.Host) bool {
+		return h.Appliance.Loglevel == want.Loglevel &&
+			h.Appliance.MaxCloneDensity == want.MaxCloneDensity &&
+			slices.Equal(ntpServerList(h.Appliance.Ntp), ntpServerList(want.Ntp)) &&
+			h.Appliance.Timezone == want.Timezone
+	})
+	return err
 }
 
 func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -135,7 +269,7 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 	}
 	if hostid == "" {
 		retries := 1
-		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *retry.RetryError {
+		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *retry.RetryError {
 			task, err := client.JoinHost(d.Get("username").(string), d.Get("password").(string), hostIP)
 			if err != nil {
 				if retries > 0 && strings.Contains(err.Error(), "InternalServer") {
@@ -161,24 +295,17 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 	} else {
 		d.Set("existing_host", true)
 	}
-	host, err := client.GetHost(hostid)
+	timeout := d.Timeout(schema.TimeoutCreate)
+	//wait for the host to finish joining
+	host, err := waitForHost(ctx, client, hostid, timeout, "join the cluster", func(host *rest.Host) bool {
+		return host.State == "available" || host.State == "maintenance"
+	})
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	// Add a delay to ensure the host is fully joined
-	time.Sleep(5 * time.Second)
 	gatewayOnly := d.Get("gateway_only").(bool)
-	if gatewayOnly && host.Appliance.Role != "gateway" {
-		if err := host.ChangeGatewayMode(client, true); err != nil {
-			return diag.FromErr(err)
-		}
-		time.Sleep(5 * time.Second) //TODO: change this api to return a task
-	} else if !gatewayOnly && host.Appliance.Role == "gateway" {
-		if err := host.ChangeGatewayMode(client, false); err != nil {
-			return diag.FromErr(err)
-		}
-		time.Sleep(5 * time.Second) //TODO: change this api to return a task
-		host, err = client.GetHost(d.Id())
+	if gatewayOnly != (host.Appliance.Role == "gateway") {
+		host, err = setHostGatewayMode(ctx, client, &host, gatewayOnly, timeout)
 		if err != nil {
 			return diag.FromErr(err)
 		}
@@ -186,57 +313,15 @@ func resourceHostCreate(ctx context.Context, d *schema.ResourceData, m interface
 
 	state := d.Get("state").(string)
 	if !gatewayOnly && host.State != state {
-		task, err := host.SetState(client, state)
+		host, err = setHostState(ctx, client, &host, state, timeout)
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		task, err = task.WaitForTaskWithContext(ctx, client, false)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		if task.State == "failed" {
-			return diag.Errorf("Failed to set host state: %s", task.Message)
-		}
 	}
-	time.Sleep(10 * time.Second)
-	host, err = client.GetHost(hostid)
+	err = updateHostAppliance(ctx, d, client, &host, timeout)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	updateAppliance := false
-	if logLevel, ok := d.Get("log_level").(string); ok {
-		if logLevel != "" && host.Appliance.Loglevel != logLevel {
-			updateAppliance = true
-			host.Appliance.Loglevel = logLevel
-		}
-	}
-	if mcd, ok := d.Get("max_clone_density").(int); ok {
-		if mcd != 0 && host.Appliance.MaxCloneDensity != mcd {
-			updateAppliance = true
-			host.Appliance.MaxCloneDensity = mcd
-		}
-	}
-	if ntpServers, ok := d.Get("ntp_servers").(string); ok {
-		if host.Appliance.Ntp != ntpServers {
-			updateAppliance = true
-			host.Appliance.Ntp = ntpServers
-		}
-	}
-	if timezone, ok := d.Get("timezone").(string); ok {
-		if host.Appliance.Timezone != timezone {
-			updateAppliance = true
-			host.Appliance.Timezone = timezone
-		}
-	}
-
-	if updateAppliance {
-		_, err = host.UpdateAppliance(client)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		//wait for appliance configure task to finish since the resonse does not include the id
-		time.Sleep(5 * time.Second)
-	}
 	d.SetId(host.Hostid)
 	return resourceHostRead(ctx, d, m)
 }
@@ -263,6 +348,7 @@ func resourceHostRead(ctx context.Context, d *schema.ResourceData, m interface{}
 	d.Set("max_clone_density", host.Appliance.MaxCloneDensity)
 	d.Set("ntp_servers", host.Appliance.Ntp)
 	d.Set("timezone", host.Appliance.Timezone)
+	d.Set("tags", host.Tags)
 	return diag.Diagnostics{}
 }
 
@@ -275,73 +361,28 @@ func resourceHostUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	timeout := d.Timeout(schema.TimeoutUpdate)
 	gatewayOnly := d.Get("gateway_only").(bool)
-	if gatewayOnly && host.Appliance.Role != "gateway" {
-		if err := host.ChangeGatewayMode(client, true); err != nil {
-			return diag.FromErr(err)
-		}
-		time.Sleep(5 * time.Second) //TODO: change this api to return a task
-		d.SetId(host.Hostid)
-		return resourceHostRead(ctx, d, m)
-	} else if !gatewayOnly && host.Appliance.Role == "gateway" {
-		if err := host.ChangeGatewayMode(client, false); err != nil {
-			return diag.FromErr(err)
-		}
-		time.Sleep(5 * time.Second) //TODO: change this api to return a task
-		host, err = client.GetHost(d.Id())
+	if gatewayOnly != (host.Appliance.Role == "gateway") {
+		host, err = setHostGatewayMode(ctx, client, &host, gatewayOnly, timeout)
 		if err != nil {
 			return diag.FromErr(err)
 		}
+		if gatewayOnly {
+			return resourceHostRead(ctx, d, m)
+		}
 	}
 
 	state := d.Get("state").(string)
 	if !gatewayOnly && host.State != state {
-		task, err := host.SetState(client, state)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		task, err = task.WaitForTaskWithContext(ctx, client, false)
+		host, err = setHostState(ctx, client, &host, state, timeout)
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		if task.State == "failed" {
-			return diag.Errorf("Failed to set host state: %s", task.Message)
-		}
 	}
-
-	updateAppliance := false
-	if logLevel, ok := d.Get("log_level").(string); ok {
-		if logLevel != "" && host.Appliance.Loglevel != logLevel {
-			updateAppliance = true
-			host.Appliance.Loglevel = logLevel
-		}
-	}
-	if mcd, ok := d.Get("max_clone_density").(int); ok {
-		if mcd != 0 && host.Appliance.MaxCloneDensity != mcd {
-			updateAppliance = true
-			host.Appliance.MaxCloneDensity = mcd
-		}
-	}
-	if ntpServers, ok := d.Get("ntp_servers").(string); ok {
-		if host.Appliance.Ntp != ntpServers {
-			updateAppliance = true
-			host.Appliance.Ntp = ntpServers
-		}
-	}
-	if timezone, ok := d.Get("timezone").(string); ok {
-		if host.Appliance.Timezone != timezone {
-			updateAppliance = true
-			host.Appliance.Timezone = timezone
-		}
-	}
-
-	if updateAppliance {
-		_, err = host.UpdateAppliance(client)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		//sleep since the api doesn't return the task
-		time.Sleep(5 * time.Second)
+	err = updateHostAppliance(ctx, d, client, &host, timeout)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	//Don't change anything for now
@@ -372,19 +413,10 @@ func resourceHostDelete(ctx context.Context, d *schema.ResourceData, m interface
 	}
 
 	if host.State == "available" {
-		task, err := host.SetState(client, "maintenance")
+		_, err = setHostState(ctx, client, &host, "maintenance", d.Timeout(schema.TimeoutDelete))
 		if err != nil {
 			return diag.FromErr(err)
 		}
-		task, err = task.WaitForTaskWithContext(ctx, client, false)
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		if task.State == "failed" {
-			return diag.Errorf("Failed to enter maintenance mode: %s", task.Message)
-		}
-		//services might still be restarting from maintenance mode
-		time.Sleep(10 * time.Second)
 	}
 
 	task, err := host.UnjoinCluster(client)
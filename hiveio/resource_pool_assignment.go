@@ -0,0 +1,229 @@
+package hiveio
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
+)
+
+func resourcePoolAssignment() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Assign a realm user or group to a guest pool for broker access.",
+		CreateContext: resourcePoolAssignmentCreate,
+		ReadContext:   resourcePoolAssignmentRead,
+		UpdateContext: resourcePoolAssignmentUpdate,
+		DeleteContext: resourcePoolAssignmentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"pool": {
+				Description: "The id of the guest pool or virtual machine.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"realm": {
+				Description: "The realm of the user or ad_group.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"username": {
+				Description: "The user assignment for broker access",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"ad_group": {
+				Description: "The active directory group assignment for broker access",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"guest_assignment": {
+				Description: "Persistent user to guest mappings for persistent pools.",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"guest": {
+							Description: "name of the guest",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"username": {
+							Description: "user assigned to the guest",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+					},
+				},
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+func resourcePoolAssignmentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	username := d.Get("username").(string)
+	adGroup := d.Get("ad_group").(string)
+	if (username == "") == (adGroup == "") {
+		return diag.Errorf("exactly one of username or ad_group must be provided")
+	}
+	pool, err := client.GetPool(d.Get("pool").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if d.Get("guest_assignment.#").(int) > 0 && (pool.GuestProfile == nil || !pool.GuestProfile.Persistent) {
+		return diag.Errorf("guest_assignment can only be used with a persistent pool")
+	}
+	err = pool.Assign(client, d.Get("realm").(string), username, adGroup)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(pool.ID)
+	for _, assignment := range d.Get("guest_assignment").([]interface{}) {
+		assignment := assignment.(map[string]interface{})
+		_, err = client.AssignGuest(pool.ID, assignment["username"].(string), d.Get("realm").(string), assignment["guest"].(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return resourcePoolAssignmentRead(ctx, d, m)
+}
+
+func resourcePoolAssignmentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	pool, err := client.GetPool(d.Id())
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		d.SetId("")
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	if pool.Assignment == nil || (pool.Assignment.Username == "" && pool.Assignment.ADGroup == "") {
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+	d.Set("pool", pool.ID)
+	d.Set("realm", pool.Assignment.Realm)
+	d.Set("username", pool.Assignment.Username)
+	d.Set("ad_group", pool.Assignment.ADGroup)
+
+	guests, err := client.ListGuests("poolId=" + url.QueryEscape(pool.ID))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	assigned := map[string]string{}
+	for _, guest := range guests {
+		if guest.Username != "" {
+			assigned[guest.Name] = guest.Username
+		}
+	}
+	configured := d.Get("guest_assignment").([]interface{})
+	guestAssignments := make([]interface{}, 0, len(assigned))
+	if len(configured) > 0 {
+		//keep the configured order so only changed users show up in the plan
+		for _, assignment := range configured {
+			guest := assignment.(map[string]interface{})["guest"].(string)
+			guestAssignments = append(guestAssignments, map[string]interface{}{
+				"guest":    guest,
+				"username": assigned[guest],
+			})
+		}
+	} else if pool.GuestProfile != nil && pool.GuestProfile.Persistent {
+		for _, guest := range guests {
+			if guest.Username != "" {
+				guestAssignments = append(guestAssignments, map[string]interface{}{
+					"guest":    guest.Name,
+					"username": guest.Username,
+				})
+			}
+		}
+	}
+	if err := d.Set("guest_assignment", guestAssignments); err != nil {
+		return diag.FromErr(err)
+	}
+	return diag.Diagnostics{}
+}
+
+func resourcePoolAssignmentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if d.HasChange("guest_assignment") {
+		oldList, newList := d.GetChange("guest_assignment")
+		current := map[string]string{}
+		for _, assignment := range oldList.([]interface{}) {
+			assignment := assignment.(map[string]interface{})
+			current[assignment["guest"].(string)] = assignment["username"].(string)
+		}
+		wanted := map[string]string{}
+		for _, assignment := range newList.([]interface{}) {
+			assignment := assignment.(map[string]interface{})
+			wanted[assignment["guest"].(string)] = assignment["username"].(string)
+		}
+		for guest, username := range current {
+			if username != "" && wanted[guest] != username {
+				err = client.ReleaseGuest(d.Id(), username, guest)
+				if err != nil {
+					return diag.FromErr(fmt.Errorf("failed to release guest %s: %w", guest, err))
+				}
+			}
+		}
+		for guest, username := range wanted {
+			if current[guest] == username {
+				continue
+			}
+			_, err = client.AssignGuest(d.Id(), username, d.Get("realm").(string), guest)
+			if err != nil {
+				return diag.FromErr(fmt.Errorf("failed to assign guest %s: %w", guest, err))
+			}
+		}
+	}
+	return resourcePoolAssignmentRead(ctx, d, m)
+}
+
+func resourcePoolAssignmentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	var pool *rest.Pool
+	pool, err = client.GetPool(d.Id())
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, assignment := range d.Get("guest_assignment").([]interface{}) {
+		assignment := assignment.(map[string]interface{})
+		if assignment["username"].(string) == "" {
+			continue
+		}
+		err = client.ReleaseGuest(pool.ID, assignment["username"].(string), assignment["guest"].(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	err = pool.DeleteAssignment(client)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return diag.Diagnostics{}
+}
@@ -3,12 +3,12 @@ package hiveio
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
 )
 
 func resourceSharedStorage() *schema.Resource {
@@ -29,10 +29,11 @@ func resourceSharedStorage() *schema.Resource {
 				ForceNew:    true,
 			},
 			"utilization": {
-				Type:     schema.TypeInt,
-				Default:  75,
-				Optional: true,
-				ForceNew: true,
+				Type:        schema.TypeInt,
+				Description: "percentage of host storage to allocate for shared storage",
+				Default:     75,
+				Optional:    true,
+				ForceNew:    true,
 			},
 			"name": {
 				Type:        schema.TypeString,
@@ -44,6 +45,42 @@ func resourceSharedStorage() *schema.Resource {
 				Description: "storage pool type",
 				Computed:    true,
 			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"state": {
+				Type:        schema.TypeString,
+				Description: "health state of the shared storage",
+				Computed:    true,
+			},
+			"replicated": {
+				Type:        schema.TypeBool,
+				Description: "true if the shared storage data is replicated between hosts",
+				Computed:    true,
+			},
+			"host_count": {
+				Type:        schema.TypeInt,
+				Description: "number of hosts providing shared storage",
+				Computed:    true,
+			},
+			"members": {
+				Type:        schema.TypeList,
+				Description: "hosts providing shared storage",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"hostid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
 			"hosts": {
 				Type:        schema.TypeList,
 				Description: "helper field to add a dependency on hosts which are added to the cluster at the same time",
@@ -56,11 +93,49 @@ func resourceSharedStorage() *schema.Resource {
 			"provider_override": &providerOverride,
 		},
 		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
 			Delete: schema.DefaultTimeout(3 * time.Minute),
 		},
 	}
 }
 
+// waitForMinimumHosts polls until the cluster has enough available hosts for shared storage
+func waitForMinimumHosts(ctx context.Context, client *rest.Client, count int, timeout time.Duration) error {
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		hosts, err := client.ListHosts("")
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		available := 0
+		for _, host := range hosts {
+			if host.State == "available" && host.Appliance.Role != "gateway" {
+				available++
+			}
+		}
+		if available < count {
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(fmt.Errorf("waiting for hosts, %d of %d available", available, count))
+		}
+		return nil
+	})
+}
+
+// enableSharedStorage enables shared storage and waits for the task to finish
+func enableSharedStorage(ctx context.Context, client *rest.Client, cluster *rest.Cluster, utilization, setSize int) error {
+	task, err := cluster.EnableSharedStorage(client, utilization, setSize)
+	if err != nil {
+		return err
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return err
+	}
+	if task.State == "failed" {
+		return fmt.Errorf("failed to Enable Shared storage: %s", task.Message)
+	}
+	return nil
+}
+
 func resourceSharedStorageCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client, err := getClient(d, m)
 	if err != nil {
@@ -76,25 +151,11 @@ func resourceSharedStorageCreate(ctx context.Context, d *schema.ResourceData, m
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *retry.RetryError {
-		task, err := cluster.EnableSharedStorage(client, utilization, setSize)
-		if err != nil && strings.Contains(err.Error(), "Not enough hosts") {
-			//waitForMinimumHosts(client, clusterID, setSize, 30*time.Second)
-			time.Sleep(15 * time.Second)
-			return retry.RetryableError(fmt.Errorf("not enough hosts"))
-		} else if err != nil {
-			return retry.NonRetryableError(err)
-		}
-
-		task, err = task.WaitForTaskWithContext(ctx, client, false)
-		if err != nil {
-			return retry.NonRetryableError(err)
-		}
-		if task.State == "failed" {
-			return retry.NonRetryableError(fmt.Errorf("failed to Enable Shared storage: %s", task.Message))
-		}
-		return nil
-	})
+	err = waitForMinimumHosts(ctx, client, setSize, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = enableSharedStorage(ctx, client, &cluster, utilization, setSize)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -136,6 +197,26 @@ func resourceSharedStorageRead(ctx context.Context, d *schema.ResourceData, m in
 	d.SetId(storage.ID)
 	d.Set("name", storage.Name)
 	d.Set("type", storage.Type)
+	d.Set("replicated", storage.Replicated)
+	d.Set("enabled", cluster.SharedStorage.Enabled)
+	d.Set("state", cluster.SharedStorage.State)
+	if cluster.SharedStorage.StorageUtilization > 0 {
+		d.Set("utilization", cluster.SharedStorage.StorageUtilization)
+	}
+	if cluster.SharedStorage.MinSetSize > 0 {
+		d.Set("minimum_set_size", cluster.SharedStorage.MinSetSize)
+	}
+	members := make([]map[string]interface{}, len(cluster.SharedStorage.Hosts))
+	for i, host := range cluster.SharedStorage.Hosts {
+		members[i] = map[string]interface{}{
+			"hostid": host.Hostid,
+			"state":  host.State,
+		}
+	}
+	d.Set("host_count", len(members))
+	if err := d.Set("members", members); err != nil {
+		return diag.FromErr(err)
+	}
 	return diag.Diagnostics{}
 }
 
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -22,6 +23,7 @@ func resourceStoragePool() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: customizeDiffStoragePool,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -50,21 +52,41 @@ func resourceStoragePool() *schema.Resource {
 				ForceNew: true,
 			},
 			"username": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
+				Type:        schema.TypeString,
+				Description: "The user to mount the storage pool as. Changing it replaces the storage pool.",
+				Optional:    true,
+				ForceNew:    true,
 			},
 			"password": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				ForceNew:  true,
-				Sensitive: true,
+				Type:          schema.TypeString,
+				Description:   "The password of username. Changing it replaces the storage pool.",
+				Optional:      true,
+				ForceNew:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"password_wo"},
+			},
+			"password_wo": {
+				Type:          schema.TypeString,
+				Description:   "Write-only password that is not stored in the state. Change secrets_wo_version to apply a new value.",
+				Optional:      true,
+				Sensitive:     true,
+				WriteOnly:     true,
+				ConflictsWith: []string{"password"},
 			},
 			"key": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				ForceNew:  true,
-				Sensitive: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"key_wo"},
+			},
+			"key_wo": {
+				Type:          schema.TypeString,
+				Description:   "Write-only key that is not stored in the state. Change secrets_wo_version to apply a new value.",
+				Optional:      true,
+				Sensitive:     true,
+				WriteOnly:     true,
+				ConflictsWith: []string{"key"},
 			},
 			"mount_options": {
 				Type:     schema.TypeList,
@@ -79,6 +101,12 @@ func resourceStoragePool() *schema.Resource {
 				Required: true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
+					ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+						if !slices.Contains(storagePoolRoles, val.(string)) {
+							errs = append(errs, fmt.Errorf("%q must be one of %s", key, strings.Join(storagePoolRoles, ", ")))
+						}
+						return
+					},
 				},
 			},
 			"s3_access_key_id": {
@@ -87,10 +115,19 @@ func resourceStoragePool() *schema.Resource {
 				ForceNew: true,
 			},
 			"s3_secret_access_key": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				ForceNew:  true,
-				Sensitive: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"s3_secret_access_key_wo"},
+			},
+			"s3_secret_access_key_wo": {
+				Type:          schema.TypeString,
+				Description:   "Write-only s3_secret_access_key that is not stored in the state. Change secrets_wo_version to apply a new value.",
+				Optional:      true,
+				Sensitive:     true,
+				WriteOnly:     true,
+				ConflictsWith: []string{"s3_secret_access_key"},
 			},
 			"s3_region": {
 				Type:     schema.TypeString,
@@ -109,16 +146,18 @@ func resourceStoragePool() *schema.Resource {
 				ForceNew: true,
 			},
 			"create_filesystem": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-				ForceNew: true,
+				Type:             schema.TypeBool,
+				Optional:         true,
+				Default:          false,
+				ForceNew:         true,
+				ValidateDiagFunc: storagePoolWipeWarning("create_filesystem formats the device when it does not contain a filesystem"),
 			},
 			"clear_disk": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
-				ForceNew: true,
+				Type:             schema.TypeBool,
+				Optional:         true,
+				Default:          false,
+				ForceNew:         true,
+				ValidateDiagFunc: storagePoolWipeWarning("clear_disk erases all data on the device"),
 			},
 			"tags": {
 				Type:     schema.TypeList,
@@ -128,6 +167,12 @@ func resourceStoragePool() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"secrets_wo_version": {
+				Type:        schema.TypeInt,
+				Description: "Change to apply new values of the write-only secrets. Changing it replaces the storage pool.",
+				Optional:    true,
+				ForceNew:    true,
+			},
 			"hosts": {
 				Type:        schema.TypeList,
 				Description: "List of host IDs that should add the storage pool",
@@ -144,6 +189,111 @@ func resourceStoragePool() *schema.Resource {
 	}
 }
 
+// storagePoolWipeWarning warns at plan time when an option that wipes the ocfs2 device is enabled
+func storagePoolWipeWarning(summary string) schema.SchemaValidateDiagFunc {
+	return func(val interface{}, path cty.Path) diag.Diagnostics {
+		if !val.(bool) {
+			return nil
+		}
+		return diag.Diagnostics{{
+			Severity:      diag.Warning,
+			Summary:       summary,
+			Detail:        "The device is wiped when the storage pool is created or replaced. Make sure the device does not hold data that is still needed.",
+			AttributePath: path,
+		}}
+	}
+}
+
+var storagePoolRoles = []string{"guest", "template", "iso", "userVolume", "backup"}
+
+// storagePoolFields are the type specific fields of a storage pool
+var storagePoolFields = []string{
+	"server", "path", "url", "username", "password", "password_wo", "key", "key_wo",
+	"s3_access_key_id", "s3_secret_access_key", "s3_secret_access_key_wo", "s3_region",
+	"mount_options", "device", "fs_name", "create_filesystem", "clear_disk",
+}
+
+type storagePoolType struct {
+	required []string
+	allowed  []string
+	roles    []string
+}
+
+// storagePoolTypes lists the fields each storage pool type requires and accepts.
+// Types that are not listed are passed to the server unchecked.
+var storagePoolTypes = map[string]storagePoolType{
+	"nfs": {
+		required: []string{"server", "path"},
+		allowed:  []string{"mount_options"},
+	},
+	"cifs": {
+		required: []string{"server", "path"},
+		allowed:  []string{"username", "password", "password_wo", "mount_options"},
+	},
+	"s3": {
+		required: []string{"path"},
+		allowed:  []string{"url", "s3_access_key_id", "s3_secret_access_key", "s3_secret_access_key_wo", "s3_region"},
+		roles:    []string{"backup"},
+	},
+	"azure": {
+		required: []string{"path", "username"},
+		allowed:  []string{"url", "key", "key_wo"},
+		roles:    []string{"backup"},
+	},
+	"ocfs2": {
+		required: []string{"device"},
+		allowed:  []string{"fs_name", "create_filesystem", "clear_disk", "mount_options"},
+	},
+}
+
+func customizeDiffStoragePool(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	poolType := d.Get("type").(string)
+	isSet := func(key string) bool {
+		if strings.HasSuffix(key, "_wo") {
+			//write-only values are only available in the configuration
+			config := d.GetRawConfig()
+			return !config.IsNull() && config.IsKnown() && !config.GetAttr(key).IsNull()
+		}
+		_, ok := d.GetOk(key)
+		return ok || !d.NewValueKnown(key)
+	}
+
+	var problems []string
+	if spec, ok := storagePoolTypes[poolType]; ok {
+		for _, key := range spec.required {
+			if !isSet(key) {
+				problems = append(problems, fmt.Sprintf("%s is required for %s storage pools", key, poolType))
+			}
+		}
+		for _, key := range storagePoolFields {
+			if isSet(key) && !slices.Contains(spec.required, key) && !slices.Contains(spec.allowed, key) {
+				problems = append(problems, fmt.Sprintf("%s is not supported for %s storage pools", key, poolType))
+			}
+		}
+		if spec.roles != nil {
+			for _, role := range d.Get("roles").([]interface{}) {
+				if !slices.Contains(spec.roles, role.(string)) {
+					problems = append(problems, fmt.Sprintf("role %s is not supported for %s storage pools, allowed roles are %s", role, poolType, strings.Join(spec.roles, ", ")))
+				}
+			}
+		}
+	}
+
+	if d.NewValueKnown("mount_options") {
+		for _, option := range d.Get("mount_options").([]interface{}) {
+			option, _ := option.(string)
+			if option == "" || strings.ContainsAny(option, ", \t") {
+				problems = append(problems, fmt.Sprintf("mount option %q must be a single option without commas or spaces", option))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s storage pool: %s", poolType, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
 func storagePoolFromResource(d *schema.ResourceData) *rest.StoragePool {
 	storage := rest.StoragePool{
 		ID:   d.Id(),
@@ -172,9 +322,13 @@ func storagePoolFromResource(d *schema.ResourceData) *rest.StoragePool {
 	}
 	if password, ok := d.GetOk("password"); ok {
 		storage.Password = password.(string)
+	} else if password := writeOnlyString(d, "password_wo"); password != "" {
+		storage.Password = password
 	}
 	if key, ok := d.GetOk("key"); ok {
 		storage.Key = key.(string)
+	} else if key := writeOnlyString(d, "key_wo"); key != "" {
+		storage.Key = key
 	}
 
 	if S3AccessKeyID, ok := d.GetOk("s3_access_key_id"); ok {
@@ -182,6 +336,8 @@ func storagePoolFromResource(d *schema.ResourceData) *rest.StoragePool {
 	}
 	if s3SecretAccessKey, ok := d.GetOk("s3_secret_access_key"); ok {
 		storage.S3SecretAccessKey = s3SecretAccessKey.(string)
+	} else if s3SecretAccessKey := writeOnlyString(d, "s3_secret_access_key_wo"); s3SecretAccessKey != "" {
+		storage.S3SecretAccessKey = s3SecretAccessKey
 	}
 	if s3Region, ok := d.GetOk("s3_region"); ok {
 		storage.S3Region = s3Region.(string)
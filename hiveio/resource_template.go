@@ -2,11 +2,14 @@ package hiveio
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hive-io/hive-go-client/rest"
 )
@@ -57,16 +60,46 @@ func resourceTemplate() *schema.Resource {
 				Optional: true,
 			},
 			"state": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:        schema.TypeString,
+				Description: "state of the template",
+				Computed:    true,
 			},
 			"state_message": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:        schema.TypeString,
+				Description: "details for the current state, such as the reason the template failed",
+				Computed:    true,
+			},
+			"source": {
+				Type:        schema.TypeList,
+				Description: "Import the template from a directory created by hiveio_template_export. The disks and interfaces from the export are used unless disk or interface blocks are configured.",
+				Optional:    true,
+				ForceNew:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"storage_id": {
+							Type:        schema.TypeString,
+							Description: "id of the storage pool containing the export",
+							Required:    true,
+						},
+						"path": {
+							Type:        schema.TypeString,
+							Description: "directory of the export in the storage pool",
+							Required:    true,
+						},
+						"verify_checksum": {
+							Type:        schema.TypeBool,
+							Description: "verify the sha256 checksum of each disk before importing",
+							Default:     true,
+							Optional:    true,
+						},
+					},
+				},
 			},
 			"disk": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressTemplateSourceDiff("disk"),
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"type": {
@@ -100,8 +133,9 @@ func resourceTemplate() *schema.Resource {
 				},
 			},
 			"interface": {
-				Type:     schema.TypeList,
-				Optional: true,
+				Type:             schema.TypeList,
+				Optional:         true,
+				DiffSuppressFunc: suppressTemplateSourceDiff("interface"),
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"network": {
@@ -184,11 +218,30 @@ func resourceTemplate() *schema.Resource {
 			"provider_override": &providerOverride,
 		},
 		Timeouts: &schema.ResourceTimeout{
-			Read: schema.DefaultTimeout(time.Minute),
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Read:   schema.DefaultTimeout(time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
 		},
 	}
 }
 
+// suppressTemplateSourceDiff ignores the disks or interfaces read from an imported template
+// when they are not configured, since they come from the export
+func suppressTemplateSourceDiff(key string) schema.SchemaDiffSuppressFunc {
+	return func(k, old, new string, d *schema.ResourceData) bool {
+		config := d.GetRawConfig()
+		if config.IsNull() || !config.IsKnown() {
+			return false
+		}
+		source := config.GetAttr("source")
+		if source.IsNull() || !source.IsKnown() || source.LengthInt() == 0 {
+			return false
+		}
+		value := config.GetAttr(key)
+		return value.IsNull() || (value.IsKnown() && value.LengthInt() == 0)
+	}
+}
+
 func templateFromResource(d *schema.ResourceData) rest.Template {
 	template := rest.Template{
 		Name:               d.Get("name").(string),
@@ -265,22 +318,90 @@ func resourceTemplateCreate(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.FromErr(err)
 	}
 	template := templateFromResource(d)
+	if _, ok := d.GetOk("source"); ok {
+		err = importTemplateSource(ctx, client, d, &template)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	_, err = template.Create(client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	time.Sleep(5 * time.Second)
-	template, err = client.GetTemplate(template.Name)
+	d.SetId(template.Name)
+	err = waitForTemplate(ctx, client, template.Name, d.Timeout(schema.TimeoutCreate))
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if template.State != "available" {
-		return diag.FromErr(fmt.Errorf("template %s is not available", template.Name))
-	}
-	d.SetId(template.Name)
 	return resourceTemplateRead(ctx, d, m)
 }
 
+// importTemplateSource fills the disks and interfaces of template from an export after verifying the disk checksums
+func importTemplateSource(ctx context.Context, client *rest.Client, d *schema.ResourceData, template *rest.Template) error {
+	storage, err := client.GetStoragePool(d.Get("source.0.storage_id").(string))
+	if err != nil {
+		return err
+	}
+	dir := d.Get("source.0.path").(string)
+	manifest, err := readTemplateManifest(ctx, client, storage, dir)
+	if err != nil {
+		return err
+	}
+	var disks []*rest.TemplateDisk
+	for _, disk := range manifest.Disks {
+		filePath := path.Join(dir, disk.Filename)
+		if d.Get("source.0.verify_checksum").(bool) {
+			if disk.SHA256 == "" {
+				return fmt.Errorf("export %s has no checksum for %s", dir, disk.Filename)
+			}
+			sum, err := storageFileSHA256(ctx, client, storage, filePath)
+			if err != nil {
+				return err
+			}
+			if sum != disk.SHA256 {
+				return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filePath, disk.SHA256, sum)
+			}
+		}
+		imported := disk.TemplateDisk
+		imported.StorageID = storage.ID
+		imported.Filename = filePath
+		disks = append(disks, &imported)
+	}
+	if len(template.Disks) == 0 {
+		template.Disks = disks
+	}
+	if len(template.Interfaces) == 0 {
+		template.Interfaces = manifest.Template.Interfaces
+	}
+	return nil
+}
+
+// waitForTemplate polls the template until it is available or has failed
+func waitForTemplate(ctx context.Context, client *rest.Client, name string, timeout time.Duration) error {
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		template, err := client.GetTemplate(name)
+		if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(fmt.Errorf("waiting for template %s to be created", name))
+		} else if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		switch template.State {
+		case "available":
+			return nil
+		case "failed", "error":
+			return retry.NonRetryableError(fmt.Errorf("template %s failed: %s", name, template.StateMessage))
+		}
+		//the last error is returned on timeout so report what the template is still doing
+		msg := fmt.Sprintf("template %s is still %s", name, template.State)
+		if template.StateMessage != "" {
+			msg += ": " + template.StateMessage
+		}
+		time.Sleep(5 * time.Second)
+		return retry.RetryableError(errors.New(msg))
+	})
+}
+
 func resourceTemplateRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client, err := getClient(d, m)
 	if err != nil {
@@ -301,6 +422,8 @@ func resourceTemplateRead(ctx context.Context, d *schema.ResourceData, m interfa
 	d.Set("display_driver", template.DisplayDriver)
 	d.Set("os", template.OS)
 	d.Set("manual_agent_install", template.ManualAgentInstall)
+	d.Set("state", template.State)
+	d.Set("state_message", template.StateMessage)
 
 	disks := make([]map[string]interface{}, len(template.Disks))
 	for i, disk := range template.Disks {
@@ -355,10 +478,20 @@ func resourceTemplateUpdate(ctx context.Context, d *schema.ResourceData, m inter
 		return diag.FromErr(err)
 	}
 	template := templateFromResource(d)
+	//the update replaces the template record, keep the description that holds hiveio_template_alias aliases
+	current, err := client.GetTemplate(template.Name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template.Description = current.Description
 	_, err = template.Update(client)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = waitForTemplate(ctx, client, template.Name, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 	return resourceTemplateRead(ctx, d, m)
 }
 
@@ -0,0 +1,211 @@
+package hiveio
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
+)
+
+func resourceTemplateAlias() *schema.Resource {
+	return &schema.Resource{
+		Description:   "A stable name pointing at a template version. The alias is stored in the description of the template it points to, so it is visible on the cluster and can be imported by name. Pools that use the template attribute pick up a promotion through their normal update.",
+		CreateContext: resourceTemplateAliasCreate,
+		ReadContext:   resourceTemplateAliasRead,
+		UpdateContext: resourceTemplateAliasUpdate,
+		DeleteContext: resourceTemplateAliasDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			if d.HasChange("version") {
+				if !d.NewValueKnown("version") {
+					return d.SetNewComputed("template")
+				}
+				return d.SetNew("template", d.Get("version"))
+			}
+			return nil
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The alias name, such as win11-prod.",
+				Required:    true,
+				ForceNew:    true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if v == "" || strings.ContainsAny(v, " \t\r\n") {
+						errs = append(errs, fmt.Errorf("%q must not be empty or contain whitespace", key))
+					}
+					return
+				},
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Description: "The name of the template version the alias points to.",
+				Required:    true,
+			},
+			"template": {
+				Type:        schema.TypeString,
+				Description: "The template name to use in pools and virtual machines.",
+				Computed:    true,
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+// templateAliasPrefix marks an alias line in a template description
+const templateAliasPrefix = "terraform-alias:"
+
+// withTemplateAlias adds or removes the alias line in a template description and keeps the other lines
+func withTemplateAlias(description, alias string, add bool) string {
+	var lines []string
+	if description != "" {
+		lines = strings.Split(description, "\n")
+	}
+	lines = slices.DeleteFunc(lines, func(line string) bool {
+		return line == templateAliasPrefix+alias
+	})
+	if add {
+		lines = append(lines, templateAliasPrefix+alias)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withoutTemplateAliases removes every alias line from a template description
+func withoutTemplateAliases(description string) string {
+	lines := slices.DeleteFunc(strings.Split(description, "\n"), func(line string) bool {
+		return strings.HasPrefix(line, templateAliasPrefix)
+	})
+	return strings.Join(lines, "\n")
+}
+
+// findAliasTemplate returns the template the alias points to, or nil
+func findAliasTemplate(client *rest.Client, alias string) (*rest.Template, error) {
+	templates, err := client.ListTemplates("")
+	if err != nil {
+		return nil, err
+	}
+	for i, template := range templates {
+		if slices.Contains(strings.Split(template.Description, "\n"), templateAliasPrefix+alias) {
+			return &templates[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// setTemplateAlias adds or removes the alias on the named template
+func setTemplateAlias(ctx context.Context, d *schema.ResourceData, client *rest.Client, name string, add bool, timeout string) error {
+	template, err := client.GetTemplate(name)
+	if err != nil {
+		return err
+	}
+	description := withTemplateAlias(template.Description, d.Id(), add)
+	if description == template.Description {
+		return nil
+	}
+	template.Description = description
+	_, err = template.Update(client)
+	if err != nil {
+		return err
+	}
+	return waitForTemplate(ctx, client, template.Name, d.Timeout(timeout))
+}
+
+func resourceTemplateAliasCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	name := d.Get("name").(string)
+	existing, err := findAliasTemplate(client, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if existing != nil {
+		return diag.Errorf("alias %s already points at template %s, import it with terraform import", name, existing.Name)
+	}
+	template, err := client.GetTemplate(d.Get("version").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if template.State != "available" {
+		return diag.Errorf("template %s is %s and cannot be promoted", template.Name, template.State)
+	}
+	d.SetId(name)
+	err = setTemplateAlias(ctx, d, client, template.Name, true, schema.TimeoutCreate)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceTemplateAliasRead(ctx, d, m)
+}
+
+func resourceTemplateAliasRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := findAliasTemplate(client, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if template == nil {
+		//the alias or the version it pointed to was removed
+		d.SetId("")
+		return diag.Diagnostics{}
+	}
+	d.Set("name", d.Id())
+	d.Set("version", template.Name)
+	d.Set("template", template.Name)
+	return diag.Diagnostics{}
+}
+
+func resourceTemplateAliasUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	oldVersion, newVersion := d.GetChange("version")
+	template, err := client.GetTemplate(newVersion.(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if template.State != "available" {
+		return diag.Errorf("template %s is %s and cannot be promoted", template.Name, template.State)
+	}
+	//point the alias at the new version before removing it from the old one
+	err = setTemplateAlias(ctx, d, client, template.Name, true, schema.TimeoutUpdate)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = setTemplateAlias(ctx, d, client, oldVersion.(string), false, schema.TimeoutUpdate)
+	if err != nil && !strings.Contains(err.Error(), "\"error\": 404") {
+		return diag.FromErr(err)
+	}
+	return resourceTemplateAliasRead(ctx, d, m)
+}
+
+func resourceTemplateAliasDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := findAliasTemplate(client, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if template == nil {
+		return diag.Diagnostics{}
+	}
+	err = setTemplateAlias(ctx, d, client, template.Name, false, schema.TimeoutDelete)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,223 @@
+package hiveio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
+)
+
+func resourceTemplateBuild() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Boot a template in authoring mode, wait for the guest agent, shut the guest down, and return the template to the available state. The provider cannot run commands in the guest, so any setup or sysprep has to be started by the template image itself.",
+		CreateContext: resourceTemplateBuildCreate,
+		ReadContext:   resourceTemplateBuildRead,
+		DeleteContext: resourceTemplateBuildDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"template": {
+				Type:        schema.TypeString,
+				Description: "The name of the template to build.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Description: "Arbitrary values that rebuild the template when they change.",
+				Optional:    true,
+				ForceNew:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"wait_for_agent": {
+				Type:        schema.TypeBool,
+				Description: "Wait for the guest agent to report in before sealing the guest.",
+				Default:     true,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"seal": {
+				Type:        schema.TypeString,
+				Description: "How the guest is sealed. Allowed values are 'shutdown' to shut the guest down through the agent and 'guest' to wait for the guest to power itself off, such as after sysprep /generalize /shutdown.",
+				Default:     "shutdown",
+				Optional:    true,
+				ForceNew:    true,
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					if v != "shutdown" && v != "guest" {
+						errs = append(errs, fmt.Errorf("%q must be shutdown or guest", key))
+					}
+					return
+				},
+			},
+			"guest_name": {
+				Type:        schema.TypeString,
+				Description: "name of the authoring guest",
+				Computed:    true,
+			},
+			"agent_version": {
+				Type:        schema.TypeString,
+				Description: "agent version reported by the authoring guest",
+				Computed:    true,
+			},
+			"state": {
+				Type:        schema.TypeString,
+				Description: "state of the template after the build",
+				Computed:    true,
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+// findAuthoringGuest returns the guest created by Template.Author
+func findAuthoringGuest(client *rest.Client, templateName string) (*rest.Guest, error) {
+	guests, err := client.ListGuests("")
+	if err != nil {
+		return nil, err
+	}
+	var match *rest.Guest
+	for i, guest := range guests {
+		if guest.TemplateName != templateName || guest.PoolID != "" && !guest.Standalone {
+			continue
+		}
+		if strings.EqualFold(guest.Name, templateName) {
+			return &guests[i], nil
+		}
+		match = &guests[i]
+	}
+	if match == nil {
+		return nil, fmt.Errorf("authoring guest for template %s not found", templateName)
+	}
+	return match, nil
+}
+
+func guestPoweredOff(guest *rest.Guest) bool {
+	return guest.GuestState == "off" || guest.GuestState == "offline" || guest.GuestState == "stopped"
+}
+
+func resourceTemplateBuildCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := client.GetTemplate(d.Get("template").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if template.State != "available" {
+		return diag.Errorf("template %s is %s and cannot be authored", template.Name, template.State)
+	}
+	err = template.Author(client)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(template.Name)
+	deadline := time.Now().Add(d.Timeout(schema.TimeoutCreate))
+
+	var guest *rest.Guest
+	err = retry.RetryContext(ctx, time.Until(deadline), func() *retry.RetryError {
+		guest, err = findAuthoringGuest(client, template.Name)
+		if err != nil {
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(err)
+		}
+		if poolGuestFailed(*guest) {
+			return retry.NonRetryableError(fmt.Errorf("authoring guest %s failed: %s", guest.Name, guest.GuestState))
+		}
+		if !rest.IsGuestReady(*guest) {
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(fmt.Errorf("waiting for authoring guest %s to be ready", guest.Name))
+		}
+		if d.Get("wait_for_agent").(bool) && guest.AgentVersion == "" {
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(fmt.Errorf("waiting for the agent on guest %s", guest.Name))
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("guest_name", guest.Name)
+	d.Set("agent_version", guest.AgentVersion)
+
+	if d.Get("seal").(string) == "shutdown" {
+		err = guest.Shutdown(client)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	err = retry.RetryContext(ctx, time.Until(deadline), func() *retry.RetryError {
+		current, err := client.GetGuest(guest.Name)
+		if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+			return nil
+		} else if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		if guestPoweredOff(current) {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+		return retry.RetryableError(fmt.Errorf("waiting for guest %s to shut down", guest.Name))
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = template.ExitAuthoring(client)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = waitForTemplate(ctx, client, template.Name, time.Until(deadline))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceTemplateBuildRead(ctx, d, m)
+}
+
+func resourceTemplateBuildRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := client.GetTemplate(d.Id())
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		d.SetId("")
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("template", template.Name)
+	d.Set("state", template.State)
+	return diag.Diagnostics{}
+}
+
+func resourceTemplateBuildDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := client.GetTemplate(d.Id())
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	//the build is not reverted, only leave authoring mode if a failed build left it there
+	if template.State == "authoring" {
+		err = template.ExitAuthoring(client)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,271 @@
+package hiveio
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
+)
+
+const templateManifestName = "template.json"
+
+type templateManifestDisk struct {
+	rest.TemplateDisk
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// templateManifest is written next to the exported disks and describes the template
+type templateManifest struct {
+	Template rest.Template          `json:"template"`
+	Disks    []templateManifestDisk `json:"disks"`
+}
+
+func resourceTemplateExport() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Export a template definition and its disks to a directory in a storage pool so it can be imported on another cluster with the source block of hiveio_template.",
+		CreateContext: resourceTemplateExportCreate,
+		ReadContext:   resourceTemplateExportRead,
+		DeleteContext: resourceTemplateExportDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"template": {
+				Type:        schema.TypeString,
+				Description: "The name of the template to export.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"storage_id": {
+				Type:        schema.TypeString,
+				Description: "The id of the storage pool to export to.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Description: "The directory in the storage pool for the export.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"checksum": {
+				Type:        schema.TypeBool,
+				Description: "Record a sha256 checksum of each exported disk in the manifest. The disks are downloaded through the provider to compute it.",
+				Default:     true,
+				Optional:    true,
+				ForceNew:    true,
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Description: "Arbitrary values that export the template again when they change.",
+				Optional:    true,
+				ForceNew:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"manifest": {
+				Type:        schema.TypeString,
+				Description: "path of the manifest in the storage pool",
+				Computed:    true,
+			},
+			"disks": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"filename": {
+							Type:        schema.TypeString,
+							Description: "path of the exported disk in the storage pool",
+							Computed:    true,
+						},
+						"sha256": {
+							Type:        schema.TypeString,
+							Description: "sha256 checksum of the exported disk",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+// storageFileSHA256 downloads a file from a storage pool and returns its sha256 checksum
+func storageFileSHA256(ctx context.Context, client *rest.Client, storage *rest.StoragePool, filePath string) (string, error) {
+	resp, err := storage.DownloadWithContext(ctx, client, filePath)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("failed to download %s: %s", filePath, resp.Status)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func readTemplateManifest(ctx context.Context, client *rest.Client, storage *rest.StoragePool, dir string) (*templateManifest, error) {
+	resp, err := storage.DownloadWithContext(ctx, client, path.Join(dir, templateManifestName))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		//use the same format as the rest client so callers can check for a 404
+		return nil, fmt.Errorf("{\"error\": %d, \"message\": \"failed to download the template manifest from %s\"}", resp.StatusCode, dir)
+	}
+	var manifest templateManifest
+	err = json.NewDecoder(resp.Body).Decode(&manifest)
+	return &manifest, err
+}
+
+func writeTemplateManifest(client *rest.Client, storage *rest.StoragePool, dir string, manifest *templateManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "hiveio-template-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	return storage.Upload(client, f.Name(), path.Join(dir, templateManifestName))
+}
+
+func resourceTemplateExportCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := client.GetTemplate(d.Get("template").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	storage, err := client.GetStoragePool(d.Get("storage_id").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	dir := d.Get("path").(string)
+
+	manifest := templateManifest{Template: template}
+	manifest.Template.State = ""
+	manifest.Template.StateMessage = ""
+	manifest.Template.Disks = nil
+	used := map[string]bool{templateManifestName: true}
+	for i, disk := range template.Disks {
+		exported := templateManifestDisk{TemplateDisk: *disk}
+		exported.StorageID = ""
+		//disks from different directories or pools can share a base name
+		exported.Filename = path.Base(disk.Filename)
+		if used[exported.Filename] {
+			exported.Filename = fmt.Sprintf("disk%d-%s", i, exported.Filename)
+		}
+		used[exported.Filename] = true
+		filePath := path.Join(dir, exported.Filename)
+		task, err := client.CopyFile(disk.StorageID, disk.Filename, storage.ID, filePath)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		task, err = task.WaitForTaskWithContext(ctx, client, false)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if task.State == "failed" {
+			return diag.Errorf("failed to export disk %s: %s", disk.Filename, task.Message)
+		}
+		if d.Get("checksum").(bool) {
+			exported.SHA256, err = storageFileSHA256(ctx, client, storage, filePath)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		manifest.Disks = append(manifest.Disks, exported)
+	}
+	err = writeTemplateManifest(client, storage, dir, &manifest)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(storage.ID + "/" + dir)
+	d.Set("manifest", path.Join(dir, templateManifestName))
+	return resourceTemplateExportRead(ctx, d, m)
+}
+
+func resourceTemplateExportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	storage, err := client.GetStoragePool(d.Get("storage_id").(string))
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		d.SetId("")
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	dir := d.Get("path").(string)
+	manifest, err := readTemplateManifest(ctx, client, storage, dir)
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		//the export is gone, export it again
+		d.SetId("")
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	disks := make([]interface{}, len(manifest.Disks))
+	for i, disk := range manifest.Disks {
+		disks[i] = map[string]interface{}{
+			"filename": path.Join(dir, disk.Filename),
+			"sha256":   disk.SHA256,
+		}
+	}
+	if err := d.Set("disks", disks); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("manifest", path.Join(dir, templateManifestName))
+	return diag.Diagnostics{}
+}
+
+func resourceTemplateExportDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	storage, err := client.GetStoragePool(d.Get("storage_id").(string))
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	files := []string{d.Get("manifest").(string)}
+	for _, disk := range d.Get("disks").([]interface{}) {
+		files = append(files, disk.(map[string]interface{})["filename"].(string))
+	}
+	for _, file := range files {
+		err = storage.DeleteFile(client, file)
+		if err != nil && !strings.Contains(err.Error(), "\"error\": 404") {
+			return diag.FromErr(err)
+		}
+	}
+	return diag.Diagnostics{}
+}
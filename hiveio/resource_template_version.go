@@ -0,0 +1,197 @@
+package hiveio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hive-io/hive-go-client/rest"
+)
+
+func resourceTemplateVersion() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Create an immutable version of a template with its own copy of the disk.",
+		CreateContext: resourceTemplateVersionCreate,
+		ReadContext:   resourceTemplateVersionRead,
+		DeleteContext: resourceTemplateVersionDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"template": {
+				Type:        schema.TypeString,
+				Description: "The name of the template to copy.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Description: "The version label, such as 2024.06 or v3.",
+				Required:    true,
+				ForceNew:    true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Description: "The name of the new template. Defaults to <template>-<version>.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"storage_id": {
+				Type:        schema.TypeString,
+				Description: "The storage pool id for the copied disk. Defaults to the storage pool of the source disk.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"filename": {
+				Type:        schema.TypeString,
+				Description: "The filename of the copied disk. Defaults to <name>.qcow2.",
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+			},
+			"state": {
+				Type:        schema.TypeString,
+				Description: "state of the template version",
+				Computed:    true,
+			},
+			"provider_override": &providerOverride,
+		},
+	}
+}
+
+func resourceTemplateVersionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	source, err := client.GetTemplate(d.Get("template").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(source.Disks) == 0 {
+		return diag.Errorf("template %s has no disks to copy", source.Name)
+	}
+	name := d.Get("name").(string)
+	if name == "" {
+		name = source.Name + "-" + d.Get("version").(string)
+	}
+	storageID := d.Get("storage_id").(string)
+	if storageID == "" {
+		storageID = source.Disks[0].StorageID
+	}
+	filename := d.Get("filename").(string)
+	if filename == "" {
+		filename = name + ".qcow2"
+	}
+
+	task, err := source.Duplicate(client, name, storageID, filename)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if task.State == "failed" {
+		return diag.Errorf("failed to copy template %s: %s", source.Name, task.Message)
+	}
+	d.SetId(name)
+	err = waitForTemplate(ctx, client, name, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	//the copy keeps the description of the source, which must not carry the source's aliases
+	template, err := client.GetTemplate(name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if description := withoutTemplateAliases(template.Description); description != template.Description {
+		template.Description = description
+		_, err = template.Update(client)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = waitForTemplate(ctx, client, name, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	return resourceTemplateVersionRead(ctx, d, m)
+}
+
+func resourceTemplateVersionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := client.GetTemplate(d.Id())
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		d.SetId("")
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	d.Set("name", template.Name)
+	d.Set("state", template.State)
+	if len(template.Disks) > 0 {
+		d.Set("storage_id", template.Disks[0].StorageID)
+		d.Set("filename", template.Disks[0].Filename)
+	}
+	return diag.Diagnostics{}
+}
+
+// poolsUsingTemplate returns the names of the pools built from a template
+func poolsUsingTemplate(client *rest.Client, templateName string) ([]string, error) {
+	pools, err := client.ListGuestPools("")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, pool := range pools {
+		if pool.GuestProfile != nil && pool.GuestProfile.TemplateName == templateName {
+			names = append(names, pool.Name)
+		}
+	}
+	return names, nil
+}
+
+func resourceTemplateVersionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client, err := getClient(d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	template, err := client.GetTemplate(d.Id())
+	if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+		return diag.Diagnostics{}
+	} else if err != nil {
+		return diag.FromErr(err)
+	}
+	pools, err := poolsUsingTemplate(client, template.Name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(pools) > 0 {
+		return diag.Errorf("template version %s is still used by pools: %s", template.Name, strings.Join(pools, ", "))
+	}
+	err = template.Delete(client)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, disk := range template.Disks {
+		storage, err := client.GetStoragePool(disk.StorageID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = storage.DeleteFile(client, disk.Filename)
+		if err != nil && !strings.Contains(err.Error(), "\"error\": 404") {
+			return diag.FromErr(fmt.Errorf("failed to delete disk %s: %w", disk.Filename, err))
+		}
+	}
+	return diag.Diagnostics{}
+}
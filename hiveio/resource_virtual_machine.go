@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hive-io/hive-go-client/rest"
@@ -18,6 +19,10 @@ func resourceVM() *schema.Resource {
 		ReadContext:   resourceVMRead,
 		UpdateContext: resourceVMUpdate,
 		DeleteContext: resourceVMDelete,
+		CustomizeDiff: customdiff.All(
+			customizeDiffBackupTarget,
+			customizeDiffCapacity(vmFootprint, "cpu", "memory", "gpu", "allowed_hosts", "disk"),
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -141,12 +146,38 @@ func resourceVM() *schema.Resource {
 							Required: true,
 						},
 						"frequency": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Description:  "How often to run the backup. Allowed values are 'daily', 'weekly', and 'monthly'.",
+							Required:     true,
+							ValidateFunc: validateBackupFrequency,
 						},
 						"target": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:        schema.TypeString,
+							Description: "The id of a storage pool with the backup role.",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"restore_from": {
+				Type:        schema.TypeList,
+				Description: "Restore the virtual machine from one of its own backups when it is created. The backup must belong to the guest with the same name as this virtual machine.",
+				Optional:    true,
+				ForceNew:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"backup": {
+							Type:        schema.TypeString,
+							Description: "name of the backup to restore",
+							Required:    true,
+							ForceNew:    true,
+						},
+						"storage_id": {
+							Type:        schema.TypeString,
+							Description: "storage pool id containing the backup. Defaults to the backup target",
+							Optional:    true,
+							ForceNew:    true,
 						},
 					},
 				},
@@ -166,6 +197,7 @@ func resourceVM() *schema.Resource {
 				Default:  "",
 				Optional: true,
 			},
+			"capacity_check": &capacityCheck,
 			"allowed_hosts": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -387,10 +419,19 @@ func resourceVMCreate(ctx context.Context, d *schema.ResourceData, m interface{}
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	guestName := strings.ToUpper(pool.Name)
+	guestName = strings.ReplaceAll(guestName, " ", "_")
+
+	if _, ok := d.GetOk("restore_from"); ok {
+		//keep the pool in the state so a failed restore does not leave an unmanaged vm
+		d.SetId(pool.ID)
+		err = restoreGuest(ctx, client, guestName, d.Get("restore_from.0.storage_id").(string), d.Get("restore_from.0.backup").(string), d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	if d.Get("wait_for_ready").(bool) {
-		guestName := strings.ToUpper(pool.Name)
-		guestName = strings.ReplaceAll(guestName, " ", "_")
 		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *retry.RetryError {
 			guest, err := client.GetGuest(guestName)
 			if err != nil {
@@ -422,6 +463,54 @@ func resourceVMCreate(ctx context.Context, d *schema.ResourceData, m interface{}
 	return resourceVMRead(ctx, d, m)
 }
 
+func restoreGuest(ctx context.Context, client *rest.Client, guestName, storageID, backup string, timeout time.Duration) error {
+	var guest *rest.Guest
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		var err error
+		guest, err = client.GetGuest(guestName)
+		if err != nil && strings.Contains(err.Error(), "\"error\": 404") {
+			time.Sleep(5 * time.Second)
+			return retry.RetryableError(fmt.Errorf("waiting for guest %s", guestName))
+		} else if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if storageID == "" && guest.Backup != nil {
+		storageID = guest.Backup.TargetStorageID
+	}
+	//the restore replaces this guest's disks, so only accept one of its own backups
+	backups, err := guest.ListBackups(client, storageID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, name := range backups {
+		if name == backup {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("backup %s does not belong to guest %s in storage pool %s", backup, guestName, storageID)
+	}
+	task, err := guest.Restore(client, storageID, backup)
+	if err != nil {
+		return err
+	}
+	task, err = task.WaitForTaskWithContext(ctx, client, false)
+	if err != nil {
+		return err
+	}
+	if task.State == "failed" {
+		return fmt.Errorf("failed to restore %s from backup %s: %s", guestName, backup, task.Message)
+	}
+	return nil
+}
+
 func resourceVMRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client, err := getClient(d, m)
 	if err != nil {
@@ -537,6 +626,12 @@ func resourceVMUpdate(ctx context.Context, d *schema.ResourceData, m interface{}
 		return diag.FromErr(err)
 	}
 	pool := vmFromResource(d)
+	//keep the assignment managed by hiveio_pool_assignment
+	current, err := client.GetPool(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	pool.Assignment = current.Assignment
 	_, err = pool.Update(client)
 	if err != nil {
 		return diag.FromErr(err)